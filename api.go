@@ -3,9 +3,11 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,7 +15,8 @@ import (
 	"github.com/charmbracelet/huh"
 )
 
-const baseUrl = "http://localhost:5445/"
+// baseUrl of the lighthouse API, tests point it to a stand-in server
+var baseUrl = "http://localhost:5445/"
 
 type Machine struct {
 	Id             string
@@ -29,8 +32,17 @@ type Machine struct {
 	CPUUsage       string
 	MEMUsage       string
 	DiskUsage      string
+	VolumesUsage   string //megabytes used by volumes of environments
+	//Drained machines are removed from load balancing and deployments by the lighthouse
+	Drained bool
 }
 
+// Values of Machine.Types, a machine can have several types.
+// The lighthouse doesn't define a load balancer type, add one here only once it does
+const MACHINE_TYPE_WORKLOAD = "workload"
+const MACHINE_TYPE_BUILDER = "builder"
+const MACHINE_TYPE_LOCAL_MACHINE = "local_machine"
+
 type MachineStats struct {
 	Id              string
 	MachineId       string
@@ -39,6 +51,7 @@ type MachineStats struct {
 	TotalMemory     int64
 	AvailableDisk   int64
 	TotalDisk       int64
+	VolumesDisk     int64 //bytes used by volumes of environments, included in TotalDisk - AvailableDisk
 }
 
 /*Machines*/
@@ -75,6 +88,7 @@ func getMachines() tea.Msg {
 				machineMsg[index].CPUUsage = fmt.Sprintf("%d", machineStats.CPUUsage)
 				machineMsg[index].MEMUsage = fmt.Sprintf("%d", machineStats.AvailableMemory)
 				machineMsg[index].DiskUsage = fmt.Sprintf("%d", machineStats.AvailableDisk/(1024*1024))
+				machineMsg[index].VolumesUsage = fmt.Sprintf("%d", machineStats.VolumesDisk/(1024*1024))
 			}
 		}
 	}
@@ -110,17 +124,18 @@ func getMachineStates() []MachineStats {
 	return machineStats
 }
 
+// getMachineOptions returns options with machine names as keys and IDs as values, so renamed machines stay selected
 func getMachineOptions() ([]huh.Option[string], []Machine) {
 	machines := getMachines().(MachineMsg) //type asssertion
-	opts := []string{}
+	opts := []huh.Option[string]{}
 	for _, machine := range machines {
-		opts = append(opts, machine.Name)
+		opts = append(opts, huh.NewOption(machine.Name, machine.Id))
 	}
-	return huh.NewOptions(opts...), machines
+	return opts, machines
 
 }
 
-func postMachine(newMachineName string, newMachineTypes string) Machine {
+func postMachine(newMachineName string, newMachineTypes []string) Machine {
 	var machine Machine
 
 	// Create an HTTP client and make a GET request.
@@ -135,7 +150,7 @@ func postMachine(newMachineName string, newMachineTypes string) Machine {
 	var bodyBytes bytes.Buffer
 	templateData := map[string]string{
 		"MACHINE_NAME":  newMachineName,
-		"MACHINE_TYPES": newMachineTypes,
+		"MACHINE_TYPES": strings.Join(newMachineTypes, `","`),
 	}
 
 	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
@@ -165,6 +180,74 @@ func postMachine(newMachineName string, newMachineTypes string) Machine {
 	return machine
 }
 
+func updateMachine(editedMachine Machine) Machine {
+	var machine Machine
+
+	// JSON body
+	scriptTemplate := createTemplate("machine", `{
+		"Id":"{{.MACHINE_ID}}",
+		"Name":"{{.MACHINE_NAME}}",
+		"Types":["{{.MACHINE_TYPES}}"]
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"MACHINE_ID":    editedMachine.Id,
+		"MACHINE_NAME":  editedMachine.Name,
+		"MACHINE_TYPES": strings.Join(editedMachine.Types, `","`),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		fmt.Println("Cannot execute template for machine:", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"machine", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return machine
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return machine
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+
+	if err := dec.Decode(&machine); err == io.EOF {
+		return machine
+	} else if err != nil {
+		return machine
+	}
+
+	return machine
+}
+
+// setMachineDrained drains or undrains the machine
+func setMachineDrained(machineId string, drained bool) bool {
+	action := "undrain"
+	if drained {
+		action = "drain"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseUrl+"machine/"+machineId+"/"+action, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
 func deleteMachine(machineId string) bool {
 
 	req, err := http.NewRequest(http.MethodDelete, baseUrl+"machine/"+machineId, nil)
@@ -196,9 +279,48 @@ type Service struct {
 	Name      string
 	GitURL    string
 	ProjectId string
+	Source    string         //see SERVICE_SOURCE_IMAGE, empty for services built from Git
+	Image     ContainerImage //registry image of services that aren't built from Git
+	//Preview environments are created by the lighthouse for each pushed branch
+	//that matches PreviewBranchPattern and removed when the branch is deleted
+	PreviewEnabled        bool
+	PreviewBranchPattern  string
+	PreviewDomainTemplate string //for example {branch}.preview.example.com
+	PreviewPort           string
+	PreviewMachineIds     []string
+	//Database add-ons are provisioned by the lighthouse from a template and have no Git repository
+	DatabaseEngine       string //see DATABASE_ENGINE_POSTGRES, empty for services built from Git
+	DatabaseVersion      string
+	MachineIds           []string //machines that run the database
+	LinkedEnvironmentIds []string //environments that get connection credentials as environment variables
+	Health               string   //reported by the lighthouse for databases, see HEALTH_STATUS_HEALTHY
+	BackupStatus         string   //see BACKUP_STATUS_OK, empty if the database has never been backed up
+	LastBackupAt         time.Time
+	Backup               BackupPolicy //schedule of database backups
 }
 type ServicesMsg []Service
 
+// Values of Service.Source
+const SERVICE_SOURCE_GIT = "git"
+const SERVICE_SOURCE_IMAGE = "image"
+
+// ContainerImage is pulled from a registry on each deployment instead of cloning and building a repository
+type ContainerImage struct {
+	Reference        string //for example ghcr.io/acme/web
+	Tag              string
+	RegistryUsername string //empty for public images
+	RegistryPassword string //a password or an access token
+	DeployOnNewTag   bool   //the lighthouse deploys environments when a new image is pushed to the tag
+}
+
+// Values of Service.DatabaseEngine
+const DATABASE_ENGINE_POSTGRES = "postgres"
+const DATABASE_ENGINE_REDIS = "redis"
+
+// Values of Service.BackupStatus
+const BACKUP_STATUS_OK = "ok"
+const BACKUP_STATUS_FAILED = "failed"
+
 func getServices() tea.Msg {
 
 	// Create an HTTP client and make a GET request.
@@ -227,7 +349,7 @@ func getServices() tea.Msg {
 	return servicesMsg
 }
 
-func postService(newServiceName string, newServiceGitURL string) Service {
+func postService(newService Service) Service {
 	var service Service
 
 	// Create an HTTP client and make a GET request.
@@ -236,13 +358,19 @@ func postService(newServiceName string, newServiceGitURL string) Service {
 	// JSON body
 	scriptTemplate := createTemplate("caddyfile", `{
 		"Name":"{{.SERVICE_NAME}}",
-		"GitURL":"{{.GIT_URL}}"
+		"GitURL":"{{.GIT_URL}}",
+		"ProjectId":"{{.PROJECT_ID}}",
+		"Source":"{{.SOURCE}}",
+		"Image":{{.IMAGE}}
 	}`)
 
 	var bodyBytes bytes.Buffer
 	templateData := map[string]string{
-		"SERVICE_NAME": newServiceName,
-		"GIT_URL":      newServiceGitURL,
+		"SERVICE_NAME": newService.Name,
+		"GIT_URL":      newService.GitURL,
+		"PROJECT_ID":   newService.ProjectId,
+		"SOURCE":       newService.Source,
+		"IMAGE":        imageJSON(newService.Image),
 	}
 
 	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
@@ -272,168 +400,162 @@ func postService(newServiceName string, newServiceGitURL string) Service {
 	return service
 }
 
-func deleteService(serviceId string) bool {
+// postDatabase asks the lighthouse to provision a database service, credentials are generated by the lighthouse
+func postDatabase(newDatabase Service) Service {
+	var service Service
 
-	req, err := http.NewRequest(http.MethodDelete, baseUrl+"service/"+serviceId, nil)
-	if err != nil {
-		return false
+	// Create an HTTP client and make a GET request.
+	c := &http.Client{Timeout: 10 * time.Second}
+
+	// JSON body
+	scriptTemplate := createTemplate("database", `{
+		"Name":"{{.SERVICE_NAME}}",
+		"ProjectId":"{{.PROJECT_ID}}",
+		"DatabaseEngine":"{{.DATABASE_ENGINE}}",
+		"DatabaseVersion":"{{.DATABASE_VERSION}}",
+		"MachineIds":{{.MACHINE_IDS}},
+		"LinkedEnvironmentIds":{{.LINKED_ENVIRONMENT_IDS}}
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"SERVICE_NAME":           newDatabase.Name,
+		"PROJECT_ID":             newDatabase.ProjectId,
+		"DATABASE_ENGINE":        newDatabase.DatabaseEngine,
+		"DATABASE_VERSION":       newDatabase.DatabaseVersion,
+		"MACHINE_IDS":            idsJSON(newDatabase.MachineIds),
+		"LINKED_ENVIRONMENT_IDS": idsJSON(newDatabase.LinkedEnvironmentIds),
 	}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return service
+	}
+
+	res, err := c.Post(baseUrl+"database", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
 	if err != nil {
-		return false
+		return service
 	}
 	defer res.Body.Close()
 
-	return true
-
-}
-
-type Environment struct {
-	Id                   string
-	Name                 string
-	Branch               string
-	GitTag               string
-	Domains              []string
-	MachineIds           []string
-	Port                 string
-	ServiceId            string
-	LastDeploymentStatus string
-}
-
-type EnvironmentsMsg []Environment
-
-func getEnvironmentsCmd(serviceId string) tea.Cmd {
+	if res.StatusCode != http.StatusOK {
+		return service
+	}
 
-	return func() tea.Msg {
-		return getEnvironments(serviceId)
+	dec := json.NewDecoder(res.Body)
+	if err := dec.Decode(&service); err != nil {
+		return Service{}
 	}
 
+	return service
 }
 
-func getEnvironments(serviceId string) tea.Msg {
-	// Create an HTTP client and make a GET request.
-	c := &http.Client{Timeout: 10 * time.Second}
-	res, err := c.Get(baseUrl + "service/" + serviceId + "/environment")
-
+// imageJSON encodes the container image for JSON body templates, registry credentials may contain quotes
+func imageJSON(image ContainerImage) string {
+	data, err := json.Marshal(image)
 	if err != nil {
-		// There was an error making our request. Wrap the error we received
-		// in a message and return it.
-		return errMsg{err}
+		return "{}"
 	}
+	return string(data)
+}
 
-	defer res.Body.Close()
-
-	// We received a response from the server. Return the HTTP status code
-	// as a message.
-	dec := json.NewDecoder(res.Body)
-
-	var environmentsMsg EnvironmentsMsg
-	if err := dec.Decode(&environmentsMsg); err == io.EOF {
-		return errMsg{err}
-	} else if err != nil {
-		return errMsg{err}
+// domainsJSON encodes domains for JSON body templates, empty domains aren't sent
+func domainsJSON(domains []string) string {
+	nonEmpty := []string{}
+	for _, domain := range domains {
+		if strings.TrimSpace(domain) != "" {
+			nonEmpty = append(nonEmpty, strings.TrimSpace(domain))
+		}
 	}
-	return environmentsMsg
+	return idsJSON(nonEmpty)
 }
 
-type NewEnvironmentAddedMsg Environment
-
-func postEnvironment(newEnvironment Environment) tea.Cmd {
+// idsJSON encodes IDs for JSON body templates, an empty list stays empty
+func idsJSON(ids []string) string {
+	if ids == nil {
+		ids = []string{}
+	}
 
-	return func() tea.Msg {
-		var environment NewEnvironmentAddedMsg
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
 
-		// Create an HTTP client and make a GET request.
-		c := &http.Client{Timeout: 10 * time.Second}
+// updateDatabaseLinks replaces environments that get connection credentials of the database
+func updateDatabaseLinks(serviceId string, environmentIds []string) bool {
 
-		// JSON body
-		scriptTemplate := createTemplate("environment", `{
-			"ServiceId":"{{.SERVICE_ID}}",
-			"Name":"{{.ENV_NAME}}",
-			"Branch":"{{.ENV_BRANCH}}",
-			"GitTag":"{{.ENV_TAG}}",
-			"Domains":["{{.ENV_DOMAIN}}"],
-			"Port":"{{.ENV_PORT}}",
-			"MachineIds":["{{.MACHINE_IDS}}"]
+	// JSON body
+	scriptTemplate := createTemplate("database", `{
+		"LinkedEnvironmentIds":{{.LINKED_ENVIRONMENT_IDS}}
 	}`)
 
-		var bodyBytes bytes.Buffer
-		templateData := map[string]string{
-			"SERVICE_ID":  newEnvironment.ServiceId,
-			"ENV_NAME":    newEnvironment.Name,
-			"ENV_BRANCH":  newEnvironment.Branch,
-			"ENV_TAG":     newEnvironment.GitTag,
-			"ENV_DOMAIN":  newEnvironment.Domains[0], //currently we can add only one domain during environment creation
-			"ENV_PORT":    newEnvironment.Port,
-			"MACHINE_IDS": strings.Join(newEnvironment.MachineIds, `","`),
-		}
-
-		if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
-			fmt.Println("Cannot execute template for Caddyfile:", err)
-		}
-
-		res, err := c.Post(baseUrl+"environment", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
-
-		if err != nil {
-			// There was an error making our request. Wrap the error we received
-			// in a message and return it.
-			return environment
-		}
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"LINKED_ENVIRONMENT_IDS": idsJSON(environmentIds),
+	}
 
-		defer res.Body.Close()
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return false
+	}
 
-		// We received a response from the server. Return the HTTP status code
-		// as a message.
-		dec := json.NewDecoder(res.Body)
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"database/"+serviceId+"/link", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
 
-		if err := dec.Decode(&environment); err == io.EOF {
-			return environment
-		} else if err != nil {
-			return environment
-		}
+	req.Header.Set("Content-Type", "application/json")
 
-		return environment
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
 	}
+	defer res.Body.Close()
 
+	return res.StatusCode == http.StatusOK
 }
 
-type EnvironmentEditedMsg Environment
-
-func updateEnvironment(newEnvironment Environment) EnvironmentEditedMsg {
-
-	var environment EnvironmentEditedMsg
+func updateService(editedService Service) Service {
+	var service Service
 
 	// JSON body
-	scriptTemplate := createTemplate("environment", `{
-			"Id":"{{.ENV_ID}}",
-			"Name":"{{.ENV_NAME}}",
-			"Branch":"{{.ENV_BRANCH}}",
-			"GitTag":"{{.ENV_TAG}}",
-			"Domains":["{{.ENV_DOMAIN}}"],
-			"Port":"{{.ENV_PORT}}",
-			"MachineIds":["{{.MACHINE_IDS}}"]
+	scriptTemplate := createTemplate("service", `{
+		"Id":"{{.SERVICE_ID}}",
+		"Name":"{{.SERVICE_NAME}}",
+		"GitURL":"{{.GIT_URL}}",
+		"ProjectId":"{{.PROJECT_ID}}",
+		"Source":"{{.SOURCE}}",
+		"Image":{{.IMAGE}},
+		"PreviewEnabled":{{.PREVIEW_ENABLED}},
+		"PreviewBranchPattern":"{{.PREVIEW_BRANCH_PATTERN}}",
+		"PreviewDomainTemplate":"{{.PREVIEW_DOMAIN_TEMPLATE}}",
+		"PreviewPort":"{{.PREVIEW_PORT}}",
+		"PreviewMachineIds":{{.PREVIEW_MACHINE_IDS}}
 	}`)
 
 	var bodyBytes bytes.Buffer
 	templateData := map[string]string{
-		"ENV_ID":      newEnvironment.Id,
-		"ENV_NAME":    newEnvironment.Name,
-		"ENV_BRANCH":  newEnvironment.Branch,
-		"ENV_TAG":     newEnvironment.GitTag,
-		"ENV_DOMAIN":  newEnvironment.Domains[0], //currently we can add only one domain during environment creation
-		"ENV_PORT":    newEnvironment.Port,
-		"MACHINE_IDS": strings.Join(newEnvironment.MachineIds, `","`),
+		"SERVICE_ID":              editedService.Id,
+		"SERVICE_NAME":            editedService.Name,
+		"GIT_URL":                 editedService.GitURL,
+		"PROJECT_ID":              editedService.ProjectId,
+		"SOURCE":                  editedService.Source,
+		"IMAGE":                   imageJSON(editedService.Image),
+		"PREVIEW_ENABLED":         fmt.Sprintf("%t", editedService.PreviewEnabled),
+		"PREVIEW_BRANCH_PATTERN":  editedService.PreviewBranchPattern,
+		"PREVIEW_DOMAIN_TEMPLATE": editedService.PreviewDomainTemplate,
+		"PREVIEW_PORT":            editedService.PreviewPort,
+		"PREVIEW_MACHINE_IDS":     idsJSON(editedService.PreviewMachineIds),
 	}
 
 	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
-		fmt.Println("Cannot execute template for Caddyfile:", err)
+		fmt.Println("Cannot execute template for service:", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, baseUrl+"environment", bytes.NewBuffer(bodyBytes.Bytes()))
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"service", bytes.NewBuffer(bodyBytes.Bytes()))
 	if err != nil {
-		// handle error
+		return service
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -441,27 +563,24 @@ func updateEnvironment(newEnvironment Environment) EnvironmentEditedMsg {
 	client := &http.Client{}
 	res, err := client.Do(req)
 	if err != nil {
-		return environment
+		return service
 	}
 	defer res.Body.Close()
 
-	// We received a response from the server. Return the HTTP status code
-	// as a message.
 	dec := json.NewDecoder(res.Body)
 
-	if err := dec.Decode(&environment); err == io.EOF {
-		return environment
+	if err := dec.Decode(&service); err == io.EOF {
+		return service
 	} else if err != nil {
-		return environment
+		return service
 	}
 
-	return environment
-
+	return service
 }
 
-func deleteEnvironment(environmentId string) bool {
+func deleteService(serviceId string) bool {
 
-	req, err := http.NewRequest(http.MethodDelete, baseUrl+"environment/"+environmentId, nil)
+	req, err := http.NewRequest(http.MethodDelete, baseUrl+"service/"+serviceId, nil)
 	if err != nil {
 		return false
 	}
@@ -477,20 +596,1070 @@ func deleteEnvironment(environmentId string) bool {
 
 }
 
-func deployEnvironment(environmentId string) bool {
+/*Projects*/
+type Project struct {
+	Id   string
+	Name string
+}
+type ProjectsMsg []Project
 
-	req, err := http.NewRequest(http.MethodGet, baseUrl+"deploy/environment/"+environmentId, nil)
-	if err != nil {
-		return false
-	}
+func getProjects() tea.Msg {
+
+	// Create an HTTP client and make a GET request.
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "project")
 
-	client := &http.Client{}
-	res, err := client.Do(req)
 	if err != nil {
-		return false
+		// There was an error making our request. Wrap the error we received
+		// in a message and return it.
+		return errMsg{err}
 	}
+
 	defer res.Body.Close()
 
-	return true
+	// We received a response from the server. Return the HTTP status code
+	// as a message.
+	dec := json.NewDecoder(res.Body)
+
+	var projectsMsg ProjectsMsg
+	if err := dec.Decode(&projectsMsg); err == io.EOF {
+		return errMsg{err}
+	} else if err != nil {
+		return errMsg{err}
+	}
+
+	return projectsMsg
+}
+
+func postProject(newProjectName string) Project {
+	var project Project
+
+	// Create an HTTP client and make a GET request.
+	c := &http.Client{Timeout: 10 * time.Second}
+
+	// JSON body
+	scriptTemplate := createTemplate("project", `{
+		"Name":"{{.PROJECT_NAME}}"
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"PROJECT_NAME": newProjectName,
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		fmt.Println("Cannot execute template for project:", err)
+	}
+
+	res, err := c.Post(baseUrl+"project", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
+
+	if err != nil {
+		return project
+	}
+
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+
+	if err := dec.Decode(&project); err == io.EOF {
+		return project
+	} else if err != nil {
+		return project
+	}
+
+	return project
+}
+
+func updateProject(editedProject Project) Project {
+	var project Project
+
+	// JSON body
+	scriptTemplate := createTemplate("project", `{
+		"Id":"{{.PROJECT_ID}}",
+		"Name":"{{.PROJECT_NAME}}"
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"PROJECT_ID":   editedProject.Id,
+		"PROJECT_NAME": editedProject.Name,
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		fmt.Println("Cannot execute template for project:", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"project", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return project
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return project
+	}
+	defer res.Body.Close()
+
+	dec := json.NewDecoder(res.Body)
+
+	if err := dec.Decode(&project); err == io.EOF {
+		return project
+	} else if err != nil {
+		return project
+	}
+
+	return project
+}
+
+func deleteProject(projectId string) bool {
+
+	req, err := http.NewRequest(http.MethodDelete, baseUrl+"project/"+projectId, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return isSuccessStatus(res.StatusCode)
+
+}
+
+type Environment struct {
+	Id                   string
+	Name                 string
+	Branch               string
+	GitTag               string
+	Domains              []string
+	MachineIds           []string
+	Port                 string
+	ServiceId            string
+	EnvironmentVariables []EnvironmentVariable
+	IsPreview            bool //created automatically for a branch, see Service.PreviewEnabled
+	LastDeploymentStatus string
+	LastDeploymentCommit string
+	Protected            bool //deploy, edit and delete require a confirmation phrase or an approval
+	FreezeWindows        []FreezeWindow
+	FreezeMode           string //what happens to deployments inside a freeze window, see FREEZE_MODE_QUEUE
+	HealthCheck          HealthCheck
+	AutoRollback         bool    //redeploy the previous commit if a new deployment fails its health check
+	Health               string  //reported by the lighthouse, see HEALTH_STATUS_HEALTHY
+	HealthMessage        string  //why the last health check failed
+	DeploymentStrategy   string  //how a new version rolls out across MachineIds, see DEPLOYMENT_STRATEGY_ALL_AT_ONCE
+	RollingBatchSize     int     //machines updated at the same time by the rolling strategy
+	CanaryPercentage     int     //machines that get the new version first with the canary strategy
+	Replicas             int     //instances of the service on each machine
+	CPULimit             float64 //CPU cores available to each instance, 0 is unlimited
+	MemoryLimit          int     //megabytes of memory available to each instance, 0 is unlimited
+	Volumes              []Volume
+}
+
+// Volume keeps data of the environment across deployments, each machine of the environment has its own copy
+type Volume struct {
+	Name      string
+	MountPath string //path inside the container
+	SizeHint  string //expected size, for example 5GB, the lighthouse doesn't enforce it
+	Used      int64  //megabytes used on a machine, reported by the lighthouse
+	Backup    BackupPolicy
+}
+
+// HealthCheck is run by the lighthouse after each deployment and then periodically,
+// an empty path disables it
+type HealthCheck struct {
+	Path           string
+	ExpectedStatus int
+	TimeoutSeconds int
+	Retries        int
+}
+
+// Values of Environment.Health, it's empty if the environment has no health check
+const HEALTH_STATUS_HEALTHY = "healthy"
+const HEALTH_STATUS_UNHEALTHY = "unhealthy"
+
+// FreezeWindow is a period without deployments, times are in UTC.
+// "Fri 12:00" - "Mon 08:00" repeats every week, "2026-12-24 00:00" - "2026-12-27 00:00" is a single period
+type FreezeWindow struct {
+	Start string
+	End   string
+}
+
+// Values of Environment.FreezeMode, webhook and manual deployments inside a freeze window
+// are queued until the window ends or rejected
+const FREEZE_MODE_QUEUE = "queue"
+const FREEZE_MODE_REJECT = "reject"
+
+// ScheduledDeployment is a deployment the lighthouse starts at ScheduledAt,
+// an empty commit deploys the latest commit of the branch
+type ScheduledDeployment struct {
+	Id            string
+	EnvironmentId string
+	Commit        string
+	ScheduledAt   time.Time
+}
+
+// Values of Environment.LastDeploymentStatus
+const DEPLOYMENT_STATUS_SCHEDULED = "scheduled"
+const DEPLOYMENT_STATUS_BUILDING = "building"
+const DEPLOYMENT_STATUS_DEPLOYING = "deploying"
+const DEPLOYMENT_STATUS_CHECKING = "checking" //the new version is running, the health check hasn't passed yet
+const DEPLOYMENT_STATUS_DEPLOYED = "deployed"
+const DEPLOYMENT_STATUS_FAILED = "failed"
+const DEPLOYMENT_STATUS_ROLLED_BACK = "rolled_back" //the health check failed and the previous commit is running again
+const DEPLOYMENT_STATUS_ABORTED = "aborted"
+const DEPLOYMENT_STATUS_PENDING = "pending" //the machine waits for its batch, only in MachineDeployment.Status
+
+// Values of Environment.DeploymentStrategy
+const DEPLOYMENT_STRATEGY_ALL_AT_ONCE = "all_at_once"
+const DEPLOYMENT_STRATEGY_ROLLING = "rolling"
+const DEPLOYMENT_STRATEGY_BLUE_GREEN = "blue_green"
+const DEPLOYMENT_STRATEGY_CANARY = "canary" //the deployment pauses after the canary machines until it's resumed
+
+// Deployment is the latest deployment of an environment with progress on each machine
+type Deployment struct {
+	Id            string
+	EnvironmentId string
+	Commit        string
+	Strategy      string
+	Status        string
+	Paused        bool
+	Machines      []MachineDeployment
+}
+
+type MachineDeployment struct {
+	MachineId string
+	Status    string
+}
+
+func isDeploymentInProgress(status string) bool {
+	return status == DEPLOYMENT_STATUS_SCHEDULED || status == DEPLOYMENT_STATUS_BUILDING || status == DEPLOYMENT_STATUS_DEPLOYING || status == DEPLOYMENT_STATUS_CHECKING
+}
+
+type EnvironmentVariable struct {
+	Name  string
+	Value string
+}
+
+type EnvironmentsMsg []Environment
+
+func getEnvironmentsCmd(serviceId string) tea.Cmd {
+
+	return func() tea.Msg {
+		return getEnvironments(serviceId)
+	}
+
+}
+
+func getEnvironments(serviceId string) tea.Msg {
+	// Create an HTTP client and make a GET request.
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "service/" + serviceId + "/environment")
+
+	if err != nil {
+		// There was an error making our request. Wrap the error we received
+		// in a message and return it.
+		return errMsg{err}
+	}
+
+	defer res.Body.Close()
+
+	// We received a response from the server. Return the HTTP status code
+	// as a message.
+	dec := json.NewDecoder(res.Body)
+
+	var environmentsMsg EnvironmentsMsg
+	if err := dec.Decode(&environmentsMsg); err == io.EOF {
+		return errMsg{err}
+	} else if err != nil {
+		return errMsg{err}
+	}
+	return environmentsMsg
+}
+
+type NewEnvironmentAddedMsg Environment
+
+func postEnvironment(newEnvironment Environment) tea.Cmd {
+
+	return func() tea.Msg {
+		var environment NewEnvironmentAddedMsg
+
+		// Create an HTTP client and make a GET request.
+		c := &http.Client{Timeout: 10 * time.Second}
+
+		// JSON body
+		scriptTemplate := createTemplate("environment", `{
+			"ServiceId":"{{.SERVICE_ID}}",
+			"Name":"{{.ENV_NAME}}",
+			"Branch":"{{.ENV_BRANCH}}",
+			"GitTag":"{{.ENV_TAG}}",
+			"Domains":{{.ENV_DOMAINS}},
+			"Port":"{{.ENV_PORT}}",
+			"MachineIds":["{{.MACHINE_IDS}}"],
+			"Protected":{{.ENV_PROTECTED}},
+			"FreezeMode":"{{.ENV_FREEZE_MODE}}",
+			"FreezeWindows":{{.ENV_FREEZE_WINDOWS}},
+			"HealthCheck":{{.ENV_HEALTH_CHECK}},
+			"AutoRollback":{{.ENV_AUTO_ROLLBACK}},
+			"DeploymentStrategy":"{{.ENV_STRATEGY}}",
+			"RollingBatchSize":{{.ENV_BATCH_SIZE}},
+			"CanaryPercentage":{{.ENV_CANARY_PERCENTAGE}},
+			"Replicas":{{.ENV_REPLICAS}},
+			"CPULimit":{{.ENV_CPU_LIMIT}},
+			"MemoryLimit":{{.ENV_MEMORY_LIMIT}},
+			"Volumes":{{.ENV_VOLUMES}},
+			"EnvironmentVariables":{{.ENV_VARIABLES}}
+	}`)
+
+		var bodyBytes bytes.Buffer
+		templateData := map[string]string{
+			"SERVICE_ID":            newEnvironment.ServiceId,
+			"ENV_NAME":              newEnvironment.Name,
+			"ENV_BRANCH":            newEnvironment.Branch,
+			"ENV_TAG":               newEnvironment.GitTag,
+			"ENV_DOMAINS":           domainsJSON(newEnvironment.Domains),
+			"ENV_PORT":              newEnvironment.Port,
+			"MACHINE_IDS":           strings.Join(newEnvironment.MachineIds, `","`),
+			"ENV_PROTECTED":         fmt.Sprintf("%t", newEnvironment.Protected),
+			"ENV_FREEZE_MODE":       newEnvironment.FreezeMode,
+			"ENV_FREEZE_WINDOWS":    freezeWindowsJSON(newEnvironment.FreezeWindows),
+			"ENV_HEALTH_CHECK":      healthCheckJSON(newEnvironment.HealthCheck),
+			"ENV_AUTO_ROLLBACK":     fmt.Sprintf("%t", newEnvironment.AutoRollback),
+			"ENV_STRATEGY":          newEnvironment.DeploymentStrategy,
+			"ENV_BATCH_SIZE":        strconv.Itoa(newEnvironment.RollingBatchSize),
+			"ENV_CANARY_PERCENTAGE": strconv.Itoa(newEnvironment.CanaryPercentage),
+			"ENV_REPLICAS":          strconv.Itoa(newEnvironment.Replicas),
+			"ENV_CPU_LIMIT":         strconv.FormatFloat(newEnvironment.CPULimit, 'f', -1, 64),
+			"ENV_MEMORY_LIMIT":      strconv.Itoa(newEnvironment.MemoryLimit),
+			"ENV_VOLUMES":           volumesJSON(newEnvironment.Volumes),
+			"ENV_VARIABLES":         environmentVariablesJSON(newEnvironment.EnvironmentVariables),
+		}
+
+		if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+			fmt.Println("Cannot execute template for Caddyfile:", err)
+		}
+
+		res, err := c.Post(baseUrl+"environment", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
+
+		if err != nil {
+			// There was an error making our request. Wrap the error we received
+			// in a message and return it.
+			return environment
+		}
+
+		defer res.Body.Close()
+
+		// We received a response from the server. Return the HTTP status code
+		// as a message.
+		dec := json.NewDecoder(res.Body)
+
+		if err := dec.Decode(&environment); err == io.EOF {
+			return environment
+		} else if err != nil {
+			return environment
+		}
+
+		return environment
+	}
+
+}
+
+// environmentVariablesJSON encodes environment variables for JSON body templates
+func environmentVariablesJSON(environmentVariables []EnvironmentVariable) string {
+	if environmentVariables == nil {
+		environmentVariables = []EnvironmentVariable{}
+	}
+
+	data, err := json.Marshal(environmentVariables)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// freezeWindowsJSON encodes freeze windows for JSON body templates
+func freezeWindowsJSON(freezeWindows []FreezeWindow) string {
+	if freezeWindows == nil {
+		freezeWindows = []FreezeWindow{}
+	}
+
+	data, err := json.Marshal(freezeWindows)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// volumesJSON encodes volumes for JSON body templates, usage is reported by the lighthouse
+func volumesJSON(volumes []Volume) string {
+	definitions := []map[string]any{}
+	for _, volume := range volumes {
+		definitions = append(definitions, map[string]any{"Name": volume.Name, "MountPath": volume.MountPath, "SizeHint": volume.SizeHint, "Backup": volume.Backup})
+	}
+
+	data, err := json.Marshal(definitions)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// healthCheckJSON encodes the health check for JSON body templates
+func healthCheckJSON(healthCheck HealthCheck) string {
+	data, err := json.Marshal(healthCheck)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// updateFreezeWindows replaces freeze windows of the environment, the lighthouse applies them to webhook deployments too
+func updateFreezeWindows(environmentId string, freezeMode string, freezeWindows []FreezeWindow) bool {
+
+	// JSON body
+	scriptTemplate := createTemplate("freeze", `{
+		"FreezeMode":"{{.FREEZE_MODE}}",
+		"FreezeWindows":{{.FREEZE_WINDOWS}}
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"FREEZE_MODE":    freezeMode,
+		"FREEZE_WINDOWS": freezeWindowsJSON(freezeWindows),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"environment/"+environmentId+"/freeze", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+func updateVolumes(environmentId string, volumes []Volume) bool {
+
+	// JSON body
+	scriptTemplate := createTemplate("volumes", `{
+		"Volumes":{{.VOLUMES}}
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"VOLUMES": volumesJSON(volumes),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"environment/"+environmentId+"/volumes", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+type EnvironmentEditedMsg Environment
+
+func updateEnvironment(newEnvironment Environment) EnvironmentEditedMsg {
+
+	var environment EnvironmentEditedMsg
+
+	// JSON body
+	scriptTemplate := createTemplate("environment", `{
+			"Id":"{{.ENV_ID}}",
+			"ServiceId":"{{.SERVICE_ID}}",
+			"Name":"{{.ENV_NAME}}",
+			"Branch":"{{.ENV_BRANCH}}",
+			"GitTag":"{{.ENV_TAG}}",
+			"Domains":{{.ENV_DOMAINS}},
+			"Port":"{{.ENV_PORT}}",
+			"MachineIds":["{{.MACHINE_IDS}}"],
+			"Protected":{{.ENV_PROTECTED}},
+			"HealthCheck":{{.ENV_HEALTH_CHECK}},
+			"AutoRollback":{{.ENV_AUTO_ROLLBACK}},
+			"DeploymentStrategy":"{{.ENV_STRATEGY}}",
+			"RollingBatchSize":{{.ENV_BATCH_SIZE}},
+			"CanaryPercentage":{{.ENV_CANARY_PERCENTAGE}},
+			"Replicas":{{.ENV_REPLICAS}},
+			"CPULimit":{{.ENV_CPU_LIMIT}},
+			"MemoryLimit":{{.ENV_MEMORY_LIMIT}},
+			"FreezeMode":"{{.ENV_FREEZE_MODE}}",
+			"FreezeWindows":{{.ENV_FREEZE_WINDOWS}},
+			"Volumes":{{.ENV_VOLUMES}},
+			"EnvironmentVariables":{{.ENV_VARIABLES}}
+	}`)
+
+	//All fields are sent, the lighthouse replaces the environment
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"ENV_ID":                newEnvironment.Id,
+		"SERVICE_ID":            newEnvironment.ServiceId,
+		"ENV_FREEZE_MODE":       newEnvironment.FreezeMode,
+		"ENV_FREEZE_WINDOWS":    freezeWindowsJSON(newEnvironment.FreezeWindows),
+		"ENV_VOLUMES":           volumesJSON(newEnvironment.Volumes),
+		"ENV_VARIABLES":         environmentVariablesJSON(newEnvironment.EnvironmentVariables),
+		"ENV_PROTECTED":         fmt.Sprintf("%t", newEnvironment.Protected),
+		"ENV_HEALTH_CHECK":      healthCheckJSON(newEnvironment.HealthCheck),
+		"ENV_AUTO_ROLLBACK":     fmt.Sprintf("%t", newEnvironment.AutoRollback),
+		"ENV_STRATEGY":          newEnvironment.DeploymentStrategy,
+		"ENV_BATCH_SIZE":        strconv.Itoa(newEnvironment.RollingBatchSize),
+		"ENV_CANARY_PERCENTAGE": strconv.Itoa(newEnvironment.CanaryPercentage),
+		"ENV_REPLICAS":          strconv.Itoa(newEnvironment.Replicas),
+		"ENV_CPU_LIMIT":         strconv.FormatFloat(newEnvironment.CPULimit, 'f', -1, 64),
+		"ENV_MEMORY_LIMIT":      strconv.Itoa(newEnvironment.MemoryLimit),
+		"ENV_NAME":              newEnvironment.Name,
+		"ENV_BRANCH":            newEnvironment.Branch,
+		"ENV_TAG":               newEnvironment.GitTag,
+		"ENV_DOMAINS":           domainsJSON(newEnvironment.Domains),
+		"ENV_PORT":              newEnvironment.Port,
+		"MACHINE_IDS":           strings.Join(newEnvironment.MachineIds, `","`),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		fmt.Println("Cannot execute template for Caddyfile:", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"environment", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		// handle error
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return environment
+	}
+	defer res.Body.Close()
+
+	// We received a response from the server. Return the HTTP status code
+	// as a message.
+	dec := json.NewDecoder(res.Body)
+
+	if err := dec.Decode(&environment); err == io.EOF {
+		return environment
+	} else if err != nil {
+		return environment
+	}
+
+	return environment
+
+}
+
+func deleteEnvironment(environmentId string) bool {
+
+	req, err := http.NewRequest(http.MethodDelete, baseUrl+"environment/"+environmentId, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return true
+
+}
+
+func deployEnvironment(environmentId string) bool {
+
+	req, err := http.NewRequest(http.MethodGet, baseUrl+"deploy/environment/"+environmentId, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return isSuccessStatus(res.StatusCode)
+
+}
+
+// isSuccessStatus reports whether the lighthouse accepted the request, deployments can be answered with 202 Accepted
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// errNoDeployment is returned by getDeployment for environments that haven't been deployed yet
+var errNoDeployment = errors.New("the environment hasn't been deployed yet")
+
+// getDeployment returns the latest deployment of the environment
+func getDeployment(environmentId string) (Deployment, error) {
+	var deployment Deployment
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "environment/" + environmentId + "/deployment")
+	if err != nil {
+		return deployment, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return deployment, errNoDeployment
+	}
+	if res.StatusCode != http.StatusOK {
+		return deployment, fmt.Errorf("the lighthouse responded with %s", res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&deployment)
+	return deployment, err
+}
+
+// getPreviousDeploymentId returns the latest deployment before a new one is requested, it's empty
+// only if the environment has never been deployed. Otherwise the previous deployment would be
+// taken for the result of the new one.
+func getPreviousDeploymentId(environmentId string) (string, error) {
+	deployment, err := getDeployment(environmentId)
+	if errors.Is(err, errNoDeployment) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot load the current deployment: %w", err)
+	}
+	return deployment.Id, nil
+}
+
+// controlDeployment pauses, resumes or aborts the deployment in progress
+func controlDeployment(environmentId string, action string) bool {
+
+	req, err := http.NewRequest(http.MethodPost, baseUrl+"environment/"+environmentId+"/deployment/"+action, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+type ScheduledDeploymentsMsg []ScheduledDeployment
+
+func getScheduledDeploymentsCmd(serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		return ScheduledDeploymentsMsg(getScheduledDeployments(serviceId))
+	}
+}
+
+func getScheduledDeployments(serviceId string) []ScheduledDeployment {
+	scheduledDeployments := []ScheduledDeployment{}
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "service/" + serviceId + "/deploy/scheduled")
+	if err != nil {
+		return scheduledDeployments
+	}
+	defer res.Body.Close()
+
+	json.NewDecoder(res.Body).Decode(&scheduledDeployments)
+	return scheduledDeployments
+}
+
+func postScheduledDeployment(environmentId string, commitHash string, scheduledAt time.Time) bool {
+
+	// JSON body
+	scriptTemplate := createTemplate("scheduled deployment", `{
+		"EnvironmentId":"{{.ENV_ID}}",
+		"Commit":"{{.COMMIT}}",
+		"ScheduledAt":"{{.SCHEDULED_AT}}"
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"ENV_ID":       environmentId,
+		"COMMIT":       commitHash,
+		"SCHEDULED_AT": scheduledAt.UTC().Format(time.RFC3339),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return false
+	}
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Post(baseUrl+"deploy/scheduled", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+func deleteScheduledDeployment(scheduledDeploymentId string) bool {
+
+	req, err := http.NewRequest(http.MethodDelete, baseUrl+"deploy/scheduled/"+scheduledDeploymentId, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// Approval is a request to deploy, edit or delete a protected environment,
+// it's recorded on the lighthouse so another user can approve it
+type Approval struct {
+	Id            string
+	EnvironmentId string
+	Action        string
+	RequestedBy   string
+	ApprovedBy    string
+	Status        string
+}
+
+// Values of Approval.Status
+const APPROVAL_STATUS_PENDING = "pending"
+const APPROVAL_STATUS_APPROVED = "approved"
+
+func getApprovals(environmentId string) []Approval {
+	approvals := []Approval{}
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "environment/" + environmentId + "/approval")
+	if err != nil {
+		return approvals
+	}
+	defer res.Body.Close()
+
+	json.NewDecoder(res.Body).Decode(&approvals)
+	return approvals
+}
+
+func getApproval(approvalId string) (Approval, error) {
+	var approval Approval
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "approval/" + approvalId)
+	if err != nil {
+		return approval, err
+	}
+	defer res.Body.Close()
+
+	err = json.NewDecoder(res.Body).Decode(&approval)
+	return approval, err
+}
+
+func postApproval(environmentId string, action string, requestedBy string) (Approval, error) {
+	var approval Approval
+
+	// JSON body
+	scriptTemplate := createTemplate("approval", `{
+		"EnvironmentId":"{{.ENV_ID}}",
+		"Action":"{{.ACTION}}",
+		"RequestedBy":"{{.REQUESTED_BY}}"
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"ENV_ID":       environmentId,
+		"ACTION":       action,
+		"REQUESTED_BY": requestedBy,
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return approval, err
+	}
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Post(baseUrl+"approval", "application/json", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return approval, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return approval, fmt.Errorf("the lighthouse responded with %s", res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&approval)
+	return approval, err
+}
+
+func approveApproval(approvalId string, approvedBy string) bool {
+
+	// JSON body
+	scriptTemplate := createTemplate("approval", `{
+		"ApprovedBy":"{{.APPROVED_BY}}"
+	}`)
+
+	var bodyBytes bytes.Buffer
+	if err := scriptTemplate.Execute(&bodyBytes, map[string]string{"APPROVED_BY": approvedBy}); err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseUrl+"approval/"+approvalId+"/approve", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// deleteApproval removes a used or canceled approval, every approval allows a single action
+func deleteApproval(approvalId string) bool {
+
+	req, err := http.NewRequest(http.MethodDelete, baseUrl+"approval/"+approvalId, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// deployEnvironmentCommit deploys a specific commit, for example the commit running in another environment
+func deployEnvironmentCommit(environmentId string, commitHash string) bool {
+
+	req, err := http.NewRequest(http.MethodGet, baseUrl+"deploy/environment/"+environmentId+"/commit/"+commitHash, nil)
+	if err != nil {
+		return false
+	}
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return isSuccessStatus(res.StatusCode)
+
+}
+
+/*Backups*/
+
+// BackupPolicy tells the lighthouse when to back up a volume or a database and how many snapshots to keep
+type BackupPolicy struct {
+	Frequency string //see BACKUP_FREQUENCY_DAILY, empty disables scheduled backups
+	Time      string //UTC, for example 03:00, hourly backups don't use it
+	Retention int    //older snapshots are deleted after each backup
+}
+
+// Values of BackupPolicy.Frequency, weekly backups run on Sunday
+const BACKUP_FREQUENCY_HOURLY = "hourly"
+const BACKUP_FREQUENCY_DAILY = "daily"
+const BACKUP_FREQUENCY_WEEKLY = "weekly"
+
+// Snapshot is a backup of a volume on one machine or of a database
+type Snapshot struct {
+	Id           string
+	ResourceType string //see BACKUP_RESOURCE_VOLUME
+	ResourceId   string //environment ID for volumes, service ID for databases
+	VolumeName   string
+	Size         int64 //bytes
+	CreatedAt    time.Time
+	Status       string
+}
+
+const BACKUP_RESOURCE_VOLUME = "volume"
+const BACKUP_RESOURCE_DATABASE = "database"
+
+// Values of Snapshot.Status
+const SNAPSHOT_STATUS_IN_PROGRESS = "in_progress"
+const SNAPSHOT_STATUS_COMPLETED = "completed"
+const SNAPSHOT_STATUS_FAILED = "failed"
+const SNAPSHOT_STATUS_RESTORING = "restoring"
+
+// BackupTarget is where the lighthouse stores snapshots, S3 fields are used by any S3-compatible storage
+type BackupTarget struct {
+	Type      string //see BACKUP_TARGET_DIRECTORY, empty until backups are set up
+	Directory string
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+const BACKUP_TARGET_DIRECTORY = "directory"
+const BACKUP_TARGET_S3 = "s3"
+
+func backupPath(resourceType string, resourceId string, volumeName string) string {
+	path := baseUrl + "backup/" + resourceType + "/" + resourceId
+	if volumeName != "" {
+		path += "/" + volumeName
+	}
+	return path
+}
+
+func getBackupTarget() (BackupTarget, error) {
+	var target BackupTarget
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(baseUrl + "backup/target")
+	if err != nil {
+		return target, err
+	}
+	defer res.Body.Close()
+
+	err = json.NewDecoder(res.Body).Decode(&target)
+	return target, err
+}
+
+func updateBackupTarget(target BackupTarget) bool {
+
+	bodyBytes, err := json.Marshal(target)
+	if err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"backup/target", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// getSnapshots returns snapshots of the volume or the database, the newest first
+func getSnapshots(resourceType string, resourceId string, volumeName string) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Get(backupPath(resourceType, resourceId, volumeName) + "/snapshot")
+	if err != nil {
+		return snapshots, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return snapshots, fmt.Errorf("the lighthouse responded with %s", res.Status)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&snapshots)
+	return snapshots, err
+}
+
+func updateBackupPolicy(resourceType string, resourceId string, volumeName string, policy BackupPolicy) bool {
+
+	// JSON body
+	scriptTemplate := createTemplate("backup", `{
+		"Frequency":"{{.FREQUENCY}}",
+		"Time":"{{.TIME}}",
+		"Retention":{{.RETENTION}}
+	}`)
+
+	var bodyBytes bytes.Buffer
+	templateData := map[string]string{
+		"FREQUENCY": policy.Frequency,
+		"TIME":      policy.Time,
+		"RETENTION": strconv.Itoa(policy.Retention),
+	}
+
+	if err := scriptTemplate.Execute(&bodyBytes, templateData); err != nil {
+		return false
+	}
+
+	req, err := http.NewRequest(http.MethodPut, backupPath(resourceType, resourceId, volumeName)+"/policy", bytes.NewBuffer(bodyBytes.Bytes()))
+	if err != nil {
+		return false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// postSnapshot starts a backup outside of the schedule
+func postSnapshot(resourceType string, resourceId string, volumeName string) bool {
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Post(backupPath(resourceType, resourceId, volumeName)+"/snapshot", "application/json", nil)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return res.StatusCode == http.StatusOK
+}
+
+// restoreSnapshot replaces current data with the snapshot, the lighthouse stops the service while data is restored
+func restoreSnapshot(snapshotId string) bool {
+
+	c := &http.Client{Timeout: 10 * time.Second}
+	res, err := c.Post(baseUrl+"backup/snapshot/"+snapshotId+"/restore", "application/json", nil)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
 
+	return res.StatusCode == http.StatusOK
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestDomainsJSON(t *testing.T) {
+	tests := []struct {
+		domains []string
+		want    string
+	}{
+		{nil, `[]`},
+		{[]string{""}, `[]`},
+		{[]string{"a.com", " b.com ", ""}, `["a.com","b.com"]`},
+	}
+	for _, test := range tests {
+		if got := domainsJSON(test.domains); got != test.want {
+			t.Errorf("domainsJSON(%q) = %s, want %s", test.domains, got, test.want)
+		}
+	}
+}
+
+// A service without preview machines isn't saved with an empty machine ID
+func TestUpdateServicePreviewMachineIds(t *testing.T) {
+	var received Service
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(received)
+	}))
+
+	for _, machineIds := range [][]string{nil, {"m1", "m2"}} {
+		updateService(Service{Id: "s1", Name: "web", PreviewMachineIds: machineIds})
+		if len(received.PreviewMachineIds) != len(machineIds) || !slices.Equal(received.PreviewMachineIds, machineIds) {
+			t.Errorf("lighthouse received preview machines %q, want %q", received.PreviewMachineIds, machineIds)
+		}
+	}
+}
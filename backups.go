@@ -0,0 +1,461 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const MENU_BACKUP_STORAGE = "Backup Storage"
+const MENU_BACKUPS = "Backups"
+
+// MENU_VOLUME_BACKUPS_PREFIX starts Environment menu items of volume backups, for example "Backups: uploads"
+const MENU_VOLUME_BACKUPS_PREFIX = MENU_BACKUPS + ": "
+
+const BACKUP_DEFAULT_TIME = "03:00"
+const BACKUP_DEFAULT_RETENTION = 7
+const SNAPSHOT_POLL_INTERVAL = 2 * time.Second
+
+var (
+	backupFrequency string
+	backupTime      string
+	backupRetention string
+	backupIsSave    bool
+)
+
+var (
+	backupTargetType      string
+	backupTargetDirectory string
+	backupTargetEndpoint  string
+	backupTargetBucket    string
+	backupTargetRegion    string
+	backupTargetAccessKey string
+	backupTargetSecretKey string
+	backupTargetIsSave    bool
+)
+
+// backupResource is a volume or a database shown on the Backups screen
+type backupResource struct {
+	resourceType string
+	resourceId   string //environment ID for volumes, service ID for databases
+	volumeName   string
+	name         string
+	policy       BackupPolicy
+}
+
+type SnapshotsMsg struct {
+	resource  backupResource
+	snapshots []Snapshot
+	target    BackupTarget
+	err       error
+}
+
+type NewBackupTargetMsg int
+
+func newBackupTargetMsg() tea.Msg {
+	var msg NewBackupTargetMsg
+	return msg
+}
+
+func volumeBackupResource(environment Environment, volume Volume) backupResource {
+	return backupResource{
+		resourceType: BACKUP_RESOURCE_VOLUME,
+		resourceId:   environment.Id,
+		volumeName:   volume.Name,
+		name:         volume.Name,
+		policy:       volume.Backup,
+	}
+}
+
+func databaseBackupResource(service Service) backupResource {
+	return backupResource{
+		resourceType: BACKUP_RESOURCE_DATABASE,
+		resourceId:   service.Id,
+		name:         service.Name,
+		policy:       service.Backup,
+	}
+}
+
+// volumeBackupMenuItems returns an Environment menu item for backups of each volume
+func volumeBackupMenuItems(environment Environment) []item {
+	items := []item{}
+	for _, volume := range environment.Volumes {
+		items = append(items, item{title: MENU_VOLUME_BACKUPS_PREFIX + volume.Name, description: ""})
+	}
+	return items
+}
+
+// showBackups opens the Backups screen of the volume or the database
+func showBackups(m *model, resource backupResource) tea.Cmd {
+	screenType = SCREEN_TYPE_BACKUPS
+	m.backupResource = resource
+	m.snapshots = SnapshotsMsg{resource: resource}
+	m.snapshotList = newSnapshotTable(m)
+	return snapshotsCmd(resource)
+}
+
+func snapshotsCmd(resource backupResource) tea.Cmd {
+	return func() tea.Msg {
+		msg := SnapshotsMsg{resource: resource}
+		msg.snapshots, msg.err = getSnapshots(resource.resourceType, resource.resourceId, resource.volumeName)
+		msg.target, _ = getBackupTarget()
+		return msg
+	}
+}
+
+// pollSnapshots reloads snapshots while a backup or a restore is running
+func pollSnapshots(resource backupResource) tea.Cmd {
+	return tea.Tick(SNAPSHOT_POLL_INTERVAL, func(t time.Time) tea.Msg {
+		return snapshotsCmd(resource)()
+	})
+}
+
+func isSnapshotRunning(snapshots []Snapshot) bool {
+	for _, snapshot := range snapshots {
+		if snapshot.Status == SNAPSHOT_STATUS_IN_PROGRESS || snapshot.Status == SNAPSHOT_STATUS_RESTORING {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameBackupResource reports whether loaded snapshots belong to the resource on the screen, its schedule may have changed
+func isSameBackupResource(a backupResource, b backupResource) bool {
+	return a.resourceType == b.resourceType && a.resourceId == b.resourceId && a.volumeName == b.volumeName
+}
+
+func isBackupScreen() bool {
+	return screenType == SCREEN_TYPE_BACKUPS || screenType == SCREEN_TYPE_BACKUP_POLICY || screenType == SCREEN_TYPE_RESTORE_CONFIRMATION
+}
+
+// snapshotRows returns rows of the Backups table
+func snapshotRows(snapshots []Snapshot) []table.Row {
+	rows := []table.Row{}
+	for _, snapshot := range snapshots {
+		rows = append(rows, table.Row{snapshot.Id, formatScheduleTime(snapshot.CreatedAt), formatSize(snapshot.Size), snapshotStatusCell(snapshot)})
+	}
+	return rows
+}
+
+func newSnapshotTable(m *model) table.Model {
+	columns := []table.Column{
+		{Title: "ID", Width: 10},
+		{Title: "Created", Width: 20},
+		{Title: "Size", Width: 10},
+		{Title: "Status", Width: 24},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(20),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.HiddenBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("255")).
+		Background(lipgloss.Color("#bfbfbf")).
+		Bold(true)
+	s.Cell = s.Cell.Height(1)
+	t.SetStyles(s)
+
+	v, _ := listStyle.GetFrameSize()
+	t.SetWidth(m.screenWidth - 2*v)
+	t.SetHeight(m.screenHeight - listTopHintHeght - 3)
+	return t
+}
+
+func snapshotStatusCell(snapshot Snapshot) string {
+	switch snapshot.Status {
+	case SNAPSHOT_STATUS_COMPLETED:
+		return deploymentDeployedStyle.Render("✓ " + snapshot.Status)
+	case SNAPSHOT_STATUS_FAILED:
+		return deploymentFailedStyle.Render("✗ " + snapshot.Status)
+	}
+	return "· " + deploymentStatusLabel(snapshot.Status)
+}
+
+// formatSize formats bytes, for example "1.5 GB"
+func formatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// backupPolicyDescription describes the schedule, for example "daily at 03:00 UTC, keeps 7 snapshots"
+func backupPolicyDescription(policy BackupPolicy) string {
+	var schedule string
+	switch policy.Frequency {
+	case BACKUP_FREQUENCY_HOURLY:
+		schedule = "hourly"
+	case BACKUP_FREQUENCY_DAILY:
+		schedule = "daily at " + policy.Time + " UTC"
+	case BACKUP_FREQUENCY_WEEKLY:
+		schedule = "weekly on Sunday at " + policy.Time + " UTC"
+	default:
+		return "no scheduled backups"
+	}
+
+	if policy.Retention == 1 {
+		return schedule + ", keeps 1 snapshot"
+	}
+	return fmt.Sprintf("%s, keeps %d snapshots", schedule, policy.Retention)
+}
+
+func validateBackupTime(str string) error {
+	if _, err := time.Parse("15:04", strings.TrimSpace(str)); err != nil {
+		return errors.New("enter the time as HH:MM, for example 03:00")
+	}
+	return nil
+}
+
+// createBackupPolicyForm asks when the volume or the database is backed up and how many snapshots are kept
+func createBackupPolicyForm(m *model) {
+
+	policy := m.backupResource.policy
+	backupFrequency = policy.Frequency
+	backupTime = policy.Time
+	if backupTime == "" {
+		backupTime = BACKUP_DEFAULT_TIME
+	}
+	backupRetention = strconv.Itoa(BACKUP_DEFAULT_RETENTION)
+	if policy.Retention > 0 {
+		backupRetention = strconv.Itoa(policy.Retention)
+	}
+	backupIsSave = true
+
+	m.backupPolicyForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Backup Schedule").
+				Description("Snapshots of "+m.backupResource.name+" are sent to the backup storage").
+				Options(
+					huh.NewOption("Off", ""),
+					huh.NewOption("Hourly", BACKUP_FREQUENCY_HOURLY),
+					huh.NewOption("Daily", BACKUP_FREQUENCY_DAILY),
+					huh.NewOption("Weekly on Sunday", BACKUP_FREQUENCY_WEEKLY),
+				).
+				Value(&backupFrequency),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Time, UTC").
+				Value(&backupTime).
+				Validate(validateBackupTime),
+		).WithHideFunc(func() bool {
+			return backupFrequency != BACKUP_FREQUENCY_DAILY && backupFrequency != BACKUP_FREQUENCY_WEEKLY
+		}),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Retention").
+				Description("Snapshots kept, older ones are deleted after each backup").
+				Value(&backupRetention).
+				Validate(validateNumber(1, 1000)),
+		).WithHideFunc(func() bool { return backupFrequency == "" }),
+		huh.NewGroup(
+			huh.NewConfirm().
+				Key("done").
+				Title("Save the backup schedule?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&backupIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.backupPolicyForm.Init()
+}
+
+// backupPolicyFromForm returns the schedule typed in the backup schedule form, fields are validated by the form
+func backupPolicyFromForm() BackupPolicy {
+	if backupFrequency == "" {
+		return BackupPolicy{}
+	}
+
+	policy := BackupPolicy{Frequency: backupFrequency}
+	if backupFrequency != BACKUP_FREQUENCY_HOURLY {
+		policy.Time = strings.TrimSpace(backupTime)
+	}
+	policy.Retention, _ = strconv.Atoi(strings.TrimSpace(backupRetention))
+	return policy
+}
+
+// createBackupTargetForm asks where the lighthouse stores backups, an S3-compatible endpoint
+// can be any service with the S3 API, for example MinIO
+func createBackupTargetForm(m *model) {
+
+	target, _ := getBackupTarget()
+	backupTargetType = target.Type
+	if backupTargetType == "" {
+		backupTargetType = BACKUP_TARGET_DIRECTORY
+	}
+	backupTargetDirectory = target.Directory
+	backupTargetEndpoint = target.Endpoint
+	backupTargetBucket = target.Bucket
+	backupTargetRegion = target.Region
+	backupTargetAccessKey = target.AccessKey
+	backupTargetSecretKey = target.SecretKey
+	backupTargetIsSave = true
+
+	isDirectory := func() bool { return backupTargetType == BACKUP_TARGET_DIRECTORY }
+
+	m.backupTargetForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Backup Storage").
+				Description("Where snapshots of volumes and databases are stored").
+				Options(
+					huh.NewOption("Directory on the lighthouse machine", BACKUP_TARGET_DIRECTORY),
+					huh.NewOption("S3-compatible storage", BACKUP_TARGET_S3),
+				).
+				Value(&backupTargetType),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Directory").
+				Placeholder("/var/backups/turbocloud").
+				Value(&backupTargetDirectory).
+				Validate(func(str string) error {
+					if !strings.HasPrefix(str, "/") {
+						return errors.New("enter an absolute path")
+					}
+					return nil
+				}),
+		).WithHideFunc(func() bool { return !isDirectory() }),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Endpoint").
+				Description("For example https://s3.eu-central-1.amazonaws.com or http://localhost:9000 for MinIO").
+				Value(&backupTargetEndpoint).
+				Validate(func(str string) error {
+					if !strings.HasPrefix(str, "http://") && !strings.HasPrefix(str, "https://") {
+						return errors.New("the endpoint should start with http:// or https://")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Bucket").
+				Value(&backupTargetBucket).
+				Validate(func(str string) error {
+					if strings.TrimSpace(str) == "" {
+						return errors.New("enter a bucket")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Region").
+				Placeholder("us-east-1").
+				Value(&backupTargetRegion),
+			huh.NewInput().
+				Title("Access Key").
+				Value(&backupTargetAccessKey),
+			huh.NewInput().
+				Title("Secret Key").
+				EchoMode(huh.EchoModePassword).
+				Value(&backupTargetSecretKey),
+		).WithHideFunc(isDirectory),
+		huh.NewGroup(
+			huh.NewConfirm().
+				Key("done").
+				Title("Save the backup storage?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&backupTargetIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.backupTargetForm.Init()
+}
+
+// backupTargetFromForm returns the storage typed in the Backup Storage form, fields of the other type are dropped
+func backupTargetFromForm() BackupTarget {
+	if backupTargetType == BACKUP_TARGET_DIRECTORY {
+		return BackupTarget{Type: BACKUP_TARGET_DIRECTORY, Directory: strings.TrimSpace(backupTargetDirectory)}
+	}
+	return BackupTarget{
+		Type:      BACKUP_TARGET_S3,
+		Endpoint:  strings.TrimSpace(backupTargetEndpoint),
+		Bucket:    strings.TrimSpace(backupTargetBucket),
+		Region:    strings.TrimSpace(backupTargetRegion),
+		AccessKey: strings.TrimSpace(backupTargetAccessKey),
+		SecretKey: backupTargetSecretKey,
+	}
+}
+
+// closeBackups returns to the Environment menu of the volume or to the Database menu
+func closeBackups(m *model) tea.Cmd {
+	if m.backupResource.resourceType == BACKUP_RESOURCE_DATABASE {
+		screenType = SCREEN_TYPE_DATABASE_MENU
+		//The Services table shows the schedule through the backup status, it's reloaded on return
+		m.selectedService.Backup = m.backupResource.policy
+		return nil
+	}
+	screenType = SCREEN_TYPE_ENV_MENU
+	return getEnvironmentsCmd(m.selectedService.Id)
+}
+
+func backupsBreadcrumb(m model) string {
+	if m.backupResource.resourceType == BACKUP_RESOURCE_DATABASE {
+		return "Services > " + m.selectedService.Name + " > " + MENU_BACKUPS
+	}
+	return "Services > " + m.selectedService.Name + " > " + m.selectedEnvironment.Name + " > " + MENU_VOLUME_BACKUPS_PREFIX + m.backupResource.name
+}
+
+// selectedSnapshot returns the snapshot selected in the Backups table
+func selectedSnapshot(m model) (Snapshot, bool) {
+	row := m.snapshotList.SelectedRow()
+	if row == nil {
+		return Snapshot{}, false
+	}
+	for _, snapshot := range m.snapshots.snapshots {
+		if snapshot.Id == row[0] {
+			return snapshot, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// backupsView shows the schedule and the storage above the snapshots table
+func backupsView(m model) string {
+	view := " Schedule: " + backupPolicyDescription(m.backupResource.policy) + "\n"
+	switch {
+	case m.snapshots.err != nil:
+		view += " " + deploymentFailedStyle.Render("Cannot load snapshots: "+m.snapshots.err.Error()) + "\n"
+	case m.snapshots.target.Type == "":
+		view += " " + deploymentFailedStyle.Render("⚠ Backup storage isn't set up, choose it in "+MENU_BACKUP_STORAGE+" on the main menu") + "\n"
+	case m.snapshots.target.Type == BACKUP_TARGET_S3:
+		view += " Storage: " + m.snapshots.target.Endpoint + "/" + m.snapshots.target.Bucket + "\n"
+	default:
+		view += " Storage: " + m.snapshots.target.Directory + "\n"
+	}
+	return view
+}
+
+func restoreConfirmationView(m model) string {
+	return fmt.Sprintf(
+		"\n Restoring replaces the current data of %s with the snapshot from %s, data written after it will be lost.\n\n %s\n\n %s\n\n %s",
+		m.backupResource.name,
+		formatScheduleTime(m.restoreSnapshot.CreatedAt),
+		confirmationHint(confirmationPhrase(true, m.backupResource.name)),
+		m.restoreConfirmation.View(),
+		"(esc to quit)")
+}
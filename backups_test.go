@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The Backup Storage form saves the target and it's loaded back the same
+func TestBackupTargetRoundTrip(t *testing.T) {
+	stored := []byte("{}")
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/backup/target" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			stored, _ = io.ReadAll(r.Body)
+			return
+		}
+		w.Write(stored)
+	}))
+
+	target := BackupTarget{Type: BACKUP_TARGET_S3, Endpoint: "https://s3.example.com", Bucket: "backups", Region: "eu-central-1", AccessKey: "key", SecretKey: "secret"}
+	if !updateBackupTarget(target) {
+		t.Fatal("updateBackupTarget() failed")
+	}
+	loaded, err := getBackupTarget()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != target {
+		t.Errorf("getBackupTarget() = %+v, want %+v", loaded, target)
+	}
+}
+
+func TestUpdateBackupPolicy(t *testing.T) {
+	var policy BackupPolicy
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/backup/"+BACKUP_RESOURCE_VOLUME+"/e1/uploads/policy" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+
+	want := BackupPolicy{Frequency: BACKUP_FREQUENCY_DAILY, Time: "03:00", Retention: 7}
+	if !updateBackupPolicy(BACKUP_RESOURCE_VOLUME, "e1", "uploads", want) {
+		t.Fatal("updateBackupPolicy() failed")
+	}
+	if policy != want {
+		t.Errorf("lighthouse received %+v, want %+v", policy, want)
+	}
+}
+
+func TestGetSnapshots(t *testing.T) {
+	snapshots := []Snapshot{
+		{Id: "s1", ResourceType: BACKUP_RESOURCE_DATABASE, ResourceId: "db1", Size: 1024, Status: SNAPSHOT_STATUS_COMPLETED},
+		{Id: "s2", ResourceType: BACKUP_RESOURCE_DATABASE, ResourceId: "db1", Status: SNAPSHOT_STATUS_IN_PROGRESS},
+	}
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/backup/" + BACKUP_RESOURCE_DATABASE + "/db1/snapshot":
+			json.NewEncoder(w).Encode(snapshots)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+
+	loaded, err := getSnapshots(BACKUP_RESOURCE_DATABASE, "db1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, snapshots) {
+		t.Errorf("getSnapshots() = %+v, want %+v", loaded, snapshots)
+	}
+	if _, err := getSnapshots(BACKUP_RESOURCE_DATABASE, "db2", ""); err == nil {
+		t.Error("getSnapshots() returned no error for an unavailable lighthouse")
+	}
+}
+
+// A snapshot is restored only after the name of the resource is typed
+func TestRestoreConfirmation(t *testing.T) {
+	restored := []string{}
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			restored = append(restored, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]Snapshot{})
+	}))
+	previousScreenType := screenType
+	t.Cleanup(func() { screenType = previousScreenType })
+
+	m := newModel()
+	m.backupResource = backupResource{resourceType: BACKUP_RESOURCE_DATABASE, resourceId: "db1", name: "pg"}
+	m.restoreSnapshot = Snapshot{Id: "s1", Status: SNAPSHOT_STATUS_COMPLETED}
+	screenType = SCREEN_TYPE_RESTORE_CONFIRMATION
+
+	m.restoreConfirmation.SetValue("postgres")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if len(restored) != 0 || screenType != SCREEN_TYPE_RESTORE_CONFIRMATION {
+		t.Fatalf("restored %q with a wrong name", restored)
+	}
+
+	m.restoreConfirmation.SetValue("pg")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !reflect.DeepEqual(restored, []string{"/backup/snapshot/s1/restore"}) {
+		t.Errorf("restore requests = %q, want /backup/snapshot/s1/restore", restored)
+	}
+	if screenType != SCREEN_TYPE_BACKUPS {
+		t.Errorf("screen = %v, want the snapshot list", screenType)
+	}
+}
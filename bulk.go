@@ -0,0 +1,358 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Number of bulk action requests sent to the lighthouse at the same time
+const BULK_PARALLELISM = 4
+
+const BULK_SELECTED_MARK = "✓"
+
+const MENU_REDEPLOY = "Redeploy"
+const MENU_CLEAR_SELECTION = "Clear Selection"
+
+const BULK_TARGET_ENVIRONMENTS = "environments"
+const BULK_TARGET_MACHINES = "machines"
+
+var bulkResultErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+type bulkItem struct {
+	id          string
+	name        string
+	environment Environment
+}
+
+type bulkResult struct {
+	name        string
+	environment Environment
+	queuedAt    time.Time //a deployment inside a freeze window starts when the window ends
+	err         error
+}
+
+type BulkResultMsg struct {
+	action  string
+	results []bulkResult
+}
+
+// toggleSelection selects or unselects the row with the ID
+func toggleSelection(selection map[string]bool, id string) {
+	if selection[id] {
+		delete(selection, id)
+	} else {
+		selection[id] = true
+	}
+}
+
+// toggleSelectAll selects all IDs or clears the selection if all of them are already selected
+func toggleSelectAll(selection map[string]bool, ids []string) {
+	allSelected := len(ids) > 0
+	for _, id := range ids {
+		if !selection[id] {
+			allSelected = false
+		}
+	}
+
+	for _, id := range ids {
+		if allSelected {
+			delete(selection, id)
+		} else {
+			selection[id] = true
+		}
+	}
+}
+
+func selectionMark(selection map[string]bool, id string) string {
+	if selection[id] {
+		return BULK_SELECTED_MARK
+	}
+	return ""
+}
+
+func selectedEnvironments(m model) []Environment {
+	environments := []Environment{}
+	for _, environment := range m.environments {
+		if m.selectedEnvironmentIds[environment.Id] {
+			environments = append(environments, environment)
+		}
+	}
+	return environments
+}
+
+func selectedMachines(m model) []Machine {
+	machines := []Machine{}
+	for _, machine := range m.machines {
+		if m.selectedMachineIds[machine.Id] {
+			machines = append(machines, machine)
+		}
+	}
+	return machines
+}
+
+func createBulkMenu(m *model) {
+	bulkMenuItems := []list.Item{}
+	if m.bulkTarget == BULK_TARGET_ENVIRONMENTS {
+		bulkMenuItems = append(bulkMenuItems, item{title: MENU_DEPLOY, description: ""})
+	}
+	bulkMenuItems = append(bulkMenuItems,
+		item{title: MENU_REDEPLOY, description: ""},
+		item{title: MENU_DELETE, description: ""},
+		item{title: MENU_CLEAR_SELECTION, description: ""},
+		item{title: MENU_BACK, description: ""},
+	)
+
+	m.bulkMenu = list.New(bulkMenuItems, envMenuItemDelegate{}, defaultWidth, listHeight)
+	m.bulkMenu.SetShowStatusBar(false)
+	m.bulkMenu.SetFilteringEnabled(false)
+	m.bulkMenu.SetShowHelp(false)
+	m.bulkMenu.SetShowTitle(false)
+
+	v, _ := listStyle.GetFrameSize()
+	m.bulkMenu.SetSize(m.screenWidth-2*v, m.screenHeight-listTopHintHeght)
+}
+
+// bulkListCmd reloads the table the bulk action was started from
+func bulkListCmd(m model) tea.Cmd {
+	if m.bulkTarget == BULK_TARGET_MACHINES {
+		return getMachines
+	}
+	return getEnvironmentsCmd(m.selectedService.Id)
+}
+
+func bulkBreadcrumb(m model) string {
+	if m.bulkTarget == BULK_TARGET_MACHINES {
+		return projectBreadcrumb(m) + "Machines > " + pluralize(len(selectedMachines(m)), "machine")
+	}
+	return "Services > " + m.selectedService.Name + " > " + pluralize(len(selectedEnvironments(m)), "environment")
+}
+
+// bulkItems returns items the action runs on, redeploying machines redeploys environments placed on them
+func bulkItems(m model, action string) ([]bulkItem, error) {
+	items := []bulkItem{}
+
+	if m.bulkTarget == BULK_TARGET_ENVIRONMENTS {
+		for _, environment := range selectedEnvironments(m) {
+			items = append(items, bulkItem{id: environment.Id, name: environment.Name, environment: environment})
+		}
+		return items, nil
+	}
+
+	machines := selectedMachines(m)
+	if action != MENU_REDEPLOY {
+		for _, machine := range machines {
+			items = append(items, bulkItem{id: machine.Id, name: machine.Name})
+		}
+		return items, nil
+	}
+
+	machineIds := []string{}
+	for _, machine := range machines {
+		machineIds = append(machineIds, machine.Id)
+	}
+	environments, err := getMachineEnvironments(machineIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, machineEnvironment := range environments {
+		environment := machineEnvironment.environment
+		items = append(items, bulkItem{id: environment.Id, name: machineEnvironment.service.Name + " > " + environment.Name, environment: environment})
+	}
+	return items, nil
+}
+
+// runBulkAction runs the action on all items with bounded parallelism
+func runBulkAction(action string, target string, items []bulkItem) tea.Cmd {
+	return func() tea.Msg {
+		results := make([]bulkResult, len(items))
+
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, BULK_PARALLELISM)
+
+		for index, bulkItem := range items {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				results[index] = runBulkItem(action, target, bulkItem)
+			}()
+		}
+
+		wg.Wait()
+
+		return BulkResultMsg{action: action, results: results}
+	}
+}
+
+func runBulkItem(action string, target string, bulkItem bulkItem) bulkResult {
+	result := bulkResult{name: bulkItem.name, environment: bulkItem.environment}
+
+	//Protected environments are deployed and deleted one by one from the Environment menu
+	if bulkItem.environment.Protected {
+		result.err = errors.New("protected, use the Environment menu")
+		return result
+	}
+
+	switch {
+	case action == MENU_DEPLOY:
+		//Inside a freeze window the deployment is queued or rejected
+		result.queuedAt, result.err = deployOutsideFreeze(bulkItem.environment, "")
+	case action == MENU_REDEPLOY:
+		if bulkItem.environment.LastDeploymentCommit == "" {
+			result.err = errors.New("nothing has been deployed yet")
+			return result
+		}
+		result.queuedAt, result.err = deployOutsideFreeze(bulkItem.environment, bulkItem.environment.LastDeploymentCommit)
+	}
+	return result
+}
+
+// bulkDeleteItems returns selected resources the bulk delete removes, environments protected
+// with a confirmation phrase or an approval are deleted one by one from the Environment menu
+func bulkDeleteItems(m model) ([]bulkItem, []string) {
+	items := []bulkItem{}
+	skipped := []string{}
+	//Selected resources are deleted as they are, nothing is loaded
+	selected, _ := bulkItems(m, MENU_DELETE)
+	for _, bulkItem := range selected {
+		if bulkItem.environment.Protected {
+			skipped = append(skipped, bulkItem.name)
+		} else {
+			items = append(items, bulkItem)
+		}
+	}
+	return items, skipped
+}
+
+// bulkDeleteTitle lists resources the bulk delete removes, skipped ones aren't listed
+func bulkDeleteTitle(m model) string {
+	items, _ := bulkDeleteItems(m)
+	names := []string{}
+	for _, bulkItem := range items {
+		names = append(names, bulkItem.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// bulkDeletePhrase requires typing names of all protected resources, see confirmationPhrase
+func bulkDeletePhrase(m model) string {
+	names := []string{}
+	if m.bulkTarget == BULK_TARGET_MACHINES {
+		for _, machine := range selectedMachines(m) {
+			if isProtectedMachine(MachineDeleteImpactMsg{machine: machine, environments: environmentsOnMachine(m.bulkDeleteEnvironments, machine.Id)}) {
+				names = append(names, machine.Name)
+			}
+		}
+	} else {
+		items, _ := bulkDeleteItems(m)
+		for _, bulkItem := range items {
+			if isProtectedEnvironment(bulkItem.environment) {
+				names = append(names, bulkItem.name)
+			}
+		}
+	}
+	return confirmationPhrase(len(names) > 0, strings.Join(names, ", "))
+}
+
+// bulkDeleteImpactView lists what is removed with the selected resources, like the delete confirmation of each of them
+func bulkDeleteImpactView(m model) string {
+	var builder strings.Builder
+
+	if m.bulkTarget == BULK_TARGET_MACHINES {
+		//Environments keep running only on machines that aren't deleted
+		remainingMachines := []Machine{}
+		for _, machine := range m.machines {
+			if !m.selectedMachineIds[machine.Id] {
+				remainingMachines = append(remainingMachines, machine)
+			}
+		}
+		for _, machine := range selectedMachines(m) {
+			builder.WriteString(" " + machine.Name)
+			if len(machine.Types) > 0 {
+				builder.WriteString(" (" + strings.Join(machine.Types, ", ") + ")")
+			}
+			builder.WriteString("\n" + machineEnvironmentsView(machine.Id, environmentsOnMachine(m.bulkDeleteEnvironments, machine.Id), remainingMachines) + "\n")
+		}
+		return builder.String()
+	}
+
+	items, skipped := bulkDeleteItems(m)
+	if len(items) > 0 {
+		builder.WriteString(" These environments will be deleted and their domains will stop serving traffic:\n\n")
+		for _, bulkItem := range items {
+			builder.WriteString(environmentDeleteLine(bulkItem.environment) + "\n")
+		}
+	}
+	if len(skipped) > 0 {
+		builder.WriteString("\n Protected environments are skipped, delete them from the Environment menu: " + strings.Join(skipped, ", ") + "\n")
+	}
+	return builder.String()
+}
+
+// bulkDelete holds back deletion of the selected resources, they can be undone together
+func bulkDelete(m *model) tea.Cmd {
+	items, _ := bulkDeleteItems(*m)
+	ids := []string{}
+	names := []string{}
+	for _, bulkItem := range items {
+		ids = append(ids, bulkItem.id)
+		names = append(names, bulkItem.name)
+	}
+
+	listCmd := bulkListCmd(*m)
+	target, serviceId := DELETE_TARGET_ENVIRONMENT, m.selectedService.Id
+	screenType = SCREEN_TYPE_ENVIRONMENTS
+	if m.bulkTarget == BULK_TARGET_MACHINES {
+		target, serviceId = DELETE_TARGET_MACHINE, ""
+		screenType = SCREEN_TYPE_MACHINES
+	}
+	clear(m.selectedEnvironmentIds)
+	clear(m.selectedMachineIds)
+
+	if len(ids) == 0 {
+		return tea.Batch(showToast(m, "Nothing to delete, all selected environments are protected"), listCmd)
+	}
+	return tea.Batch(scheduleDeletes(m, target, ids, names, serviceId), listCmd)
+}
+
+func bulkResultView(msg BulkResultMsg) string {
+	var builder strings.Builder
+
+	failed := 0
+	for _, result := range msg.results {
+		if result.err != nil {
+			failed++
+			builder.WriteString(bulkResultErrorStyle.Render(" ✗ "+result.name+": "+result.err.Error()) + "\n")
+		} else if !result.queuedAt.IsZero() {
+			builder.WriteString(" ⏲ " + result.name + ": queued for " + formatScheduleTime(result.queuedAt) + ", it's in a freeze window\n")
+		} else {
+			builder.WriteString(" ✓ " + result.name + "\n")
+		}
+	}
+
+	summary := msg.action + ": " + pluralize(len(msg.results)-failed, "item") + " succeeded"
+	if failed > 0 {
+		summary += ", " + pluralize(failed, "item") + " failed"
+	}
+	if len(msg.results) == 0 {
+		summary = msg.action + ": nothing to do"
+	}
+
+	return newMachineHintTitleStyle.Render("\n "+summary) + "\n\n" + builder.String()
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestBulkDeletePhraseRequiresNamesOfProtectedEnvironments(t *testing.T) {
+	m := model{
+		bulkTarget: BULK_TARGET_ENVIRONMENTS,
+		environments: []Environment{
+			{Id: "e1", Name: "staging"},
+			{Id: "e2", Name: "prod", Domains: []string{"example.com"}, LastDeploymentCommit: "abc"},
+			{Id: "e3", Name: "billing", Protected: true},
+		},
+		selectedEnvironmentIds: map[string]bool{"e1": true, "e2": true, "e3": true},
+	}
+
+	if phrase := bulkDeletePhrase(m); phrase != "prod" {
+		t.Errorf("bulkDeletePhrase() = %q, want prod", phrase)
+	}
+
+	//Environments protected with a phrase or an approval are deleted from the Environment menu
+	items, skipped := bulkDeleteItems(m)
+	if len(items) != 2 || len(skipped) != 1 || skipped[0] != "billing" {
+		t.Errorf("bulkDeleteItems() = %v, %q, want staging and prod, billing skipped", items, skipped)
+	}
+
+	delete(m.selectedEnvironmentIds, "e2")
+	if phrase := bulkDeletePhrase(m); phrase != "y" {
+		t.Errorf("bulkDeletePhrase() = %q, want y", phrase)
+	}
+}
+
+func TestBulkDeleteIsHeldBackForUndo(t *testing.T) {
+	m := model{
+		bulkTarget:             BULK_TARGET_ENVIRONMENTS,
+		environments:           []Environment{{Id: "e1", Name: "staging"}, {Id: "e2", Name: "dev"}},
+		selectedEnvironmentIds: map[string]bool{"e1": true, "e2": true},
+		selectedMachineIds:     map[string]bool{},
+	}
+
+	bulkDelete(&m)
+	if m.pendingDelete == nil || len(m.pendingDelete.resourceIds) != 2 {
+		t.Fatalf("pendingDelete = %+v, want both environments", m.pendingDelete)
+	}
+	if len(m.selectedEnvironmentIds) != 0 {
+		t.Error("the selection isn't cleared")
+	}
+}
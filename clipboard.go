@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptAction runs when its key is typed in a prompt shown outside of the TUI
+type promptAction struct {
+	key         string
+	description string
+	run         func() string
+}
+
+type ServiceKeysMsg struct {
+	webhookURL string
+	deployKey  string
+}
+
+// copyToClipboard copies the text with OSC52 that also works over SSH,
+// the system clipboard is a fallback for terminals that don't support OSC52
+func copyToClipboard(text string) {
+	sequence := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		sequence = sequence.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		sequence = sequence.Screen()
+	}
+	sequence.WriteTo(os.Stderr)
+
+	//The system clipboard of a remote machine isn't the clipboard of the user
+	if os.Getenv("SSH_TTY") == "" && !clipboard.Unsupported {
+		clipboard.WriteAll(text)
+	}
+}
+
+// copyAction copies the value to the clipboard
+func copyAction(key string, title string, value string) promptAction {
+	return promptAction{key: key, description: "copy the " + strings.ToLower(title), run: func() string {
+		copyToClipboard(value)
+		return title + " copied to clipboard"
+	}}
+}
+
+// ActionPrompt waits for Enter, typing the key of an action and Enter runs the action
+func ActionPrompt(label string, actions []promptAction) {
+	r := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Fprintf(os.Stderr, "%s ", label)
+		s, _ := r.ReadString('\n')
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			return
+		}
+
+		index := slices.IndexFunc(actions, func(action promptAction) bool { return action.key == s })
+		if index >= 0 {
+			fmt.Fprintf(os.Stderr, "    %s\n", actions[index].run())
+		}
+	}
+}
+
+// actionPromptLabel lists keys of the actions
+func actionPromptLabel(actions []promptAction, enterAction string) string {
+	if len(actions) == 0 {
+		return "\n    Press Enter to " + enterAction
+	}
+
+	hints := []string{}
+	for _, action := range actions {
+		hints = append(hints, "type "+action.key+" to "+action.description)
+	}
+	return "\n    Press Enter to " + enterAction + ", " + strings.Join(hints, ", ") + " and press Enter"
+}
+
+// getBuilderMachine returns the first builder machine
+func getBuilderMachine() Machine {
+	var machineBuilder Machine
+
+	machines, ok := getMachines().(MachineMsg)
+	if !ok {
+		return machineBuilder
+	}
+	for _, machine := range machines {
+		if slices.Contains(machine.Types, MACHINE_TYPE_BUILDER) {
+			machineBuilder = machine
+			break
+		}
+	}
+	return machineBuilder
+}
+
+// webhookURL returns the URL Git pushes of the service are sent to, it requires a domain of the builder machine
+func webhookURL(machineBuilder Machine, serviceId string) string {
+	return "https://" + machineBuilder.Domains[0] + "/deploy/" + serviceId
+}
+
+// deployKey returns the public SSH key of the builder machine used to clone repositories
+func deployKey(machineBuilder Machine) string {
+	return strings.Replace(machineBuilder.PublicSSHKey, "\n", "", -1)
+}
+
+func serviceKeysMsg(serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		var msg ServiceKeysMsg
+		machineBuilder := getBuilderMachine()
+		if len(machineBuilder.Domains) > 0 {
+			msg.webhookURL = webhookURL(machineBuilder, serviceId)
+		}
+		msg.deployKey = deployKey(machineBuilder)
+		return msg
+	}
+}
+
+// serviceKeysView shows the webhook URL and the deploy key wrapped to the screen width
+func serviceKeysView(m model) string {
+	v, _ := listStyle.GetFrameSize()
+	codeStyle := codeHintStyle.Width(m.screenWidth - 2*v)
+
+	webhook := "Add at least one domain to the builder machine to get the webhook URL"
+	if m.serviceKeys.webhookURL != "" {
+		webhook = codeStyle.Render(m.serviceKeys.webhookURL)
+	}
+
+	key := "The builder machine has no public SSH key yet"
+	if m.serviceKeys.deployKey != "" {
+		key = codeStyle.Render(m.serviceKeys.deployKey)
+	}
+
+	return newMachineHintTitleStyle.Render("Webhook URL (Content-Type: application/json)") + "\n\n" + webhook + "\n\n" + newMachineHintTitleStyle.Render("Deploy key (read access is enough)") + "\n\n" + key
+}
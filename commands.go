@@ -0,0 +1,715 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+)
+
+// LighthouseState is a snapshot of projects, machines, services and environments
+// used by the export and import commands
+type LighthouseState struct {
+	Projects     []Project
+	Machines     []Machine
+	Services     []Service
+	Environments []Environment
+}
+
+// runCommand runs a non-interactive command and returns the exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "export":
+		return exportCommand(args[1:])
+	case "import":
+		return importCommand(args[1:])
+	case "env":
+		return envCommand(args[1:])
+	}
+
+	fmt.Fprintln(os.Stderr, "Unknown command:", args[0])
+	fmt.Fprintln(os.Stderr, commandsUsage)
+	return 2
+}
+
+const commandsUsage = `Usage:
+  turbocloud export > state.json
+  turbocloud import state.json
+  turbocloud env diff <environment> <environment> [--show-values]
+  turbocloud env deploy <environment> [--wait] [--timeout 10m] [--confirm "deploy <name>"]
+  turbocloud env status <environment>
+  turbocloud env schedule <environment> "2026-10-20 18:00" [--confirm "deploy <name>"]
+
+Environments can be referenced by ID, name or service/name`
+
+func envCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, commandsUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "diff":
+		return envDiffCommand(args[1:])
+	case "deploy":
+		return envDeployCommand(args[1:])
+	case "schedule":
+		return envScheduleCommand(args[1:])
+	case "status":
+		return envStatusCommand(args[1:])
+	}
+
+	fmt.Fprintln(os.Stderr, "Unknown command: env", args[0])
+	fmt.Fprintln(os.Stderr, commandsUsage)
+	return 2
+}
+
+// parseFlags parses flags that can be placed before or after positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := flags.Parse(args); err != nil {
+			return positional, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func envDiffCommand(args []string) int {
+	flags := flag.NewFlagSet("env diff", flag.ContinueOnError)
+	showValues := flags.Bool("show-values", false, "show values of environment variables")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: turbocloud env diff <environment> <environment> [--show-values]")
+		return 2
+	}
+
+	state, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load lighthouse state:", err)
+		return 1
+	}
+
+	environmentA, err := findEnvironment(state, positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	environmentB, err := findEnvironment(state, positional[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	lines := diffEnvironments(environmentA, environmentB, state.Machines, *showValues)
+	fmt.Print(formatEnvironmentDiff(environmentA, environmentB, lines))
+
+	return 0
+}
+
+// findEnvironment finds an environment by ID, name or service/name
+func findEnvironment(state LighthouseState, reference string) (Environment, error) {
+	serviceNames := map[string]string{}
+	for _, service := range state.Services {
+		serviceNames[service.Id] = service.Name
+	}
+
+	found := []Environment{}
+	for _, environment := range state.Environments {
+		if environment.Id == reference {
+			return environment, nil
+		}
+		if environment.Name == reference || serviceNames[environment.ServiceId]+"/"+environment.Name == reference {
+			found = append(found, environment)
+		}
+	}
+
+	if len(found) == 0 {
+		return Environment{}, fmt.Errorf("environment %s not found", reference)
+	}
+	if len(found) > 1 {
+		return Environment{}, fmt.Errorf("there are several environments named %s, use service/name or ID", reference)
+	}
+	return found[0], nil
+}
+
+func exportCommand(args []string) int {
+	state, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot export lighthouse state:", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(state); err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot encode lighthouse state:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func getLighthouseState() (LighthouseState, error) {
+	var state LighthouseState
+
+	machines, ok := getMachines().(MachineMsg)
+	if !ok {
+		return state, fmt.Errorf("cannot load machines")
+	}
+	for _, machine := range machines {
+		//Stats and join URLs are not part of the configuration
+		machine.CPUUsage = ""
+		machine.MEMUsage = ""
+		machine.DiskUsage = ""
+		machine.VolumesUsage = ""
+		machine.JoinURL = ""
+		state.Machines = append(state.Machines, machine)
+	}
+
+	//Lighthouses without projects are still exported
+	if projects, ok := getProjects().(ProjectsMsg); ok {
+		state.Projects = projects
+	}
+
+	services, ok := getServices().(ServicesMsg)
+	if !ok {
+		return state, fmt.Errorf("cannot load services")
+	}
+	for _, service := range services {
+		//Registry passwords aren't exported, import asks for them again
+		service.Image.RegistryPassword = ""
+		state.Services = append(state.Services, service)
+	}
+
+	for _, service := range services {
+		environments, ok := getEnvironments(service.Id).(EnvironmentsMsg)
+		if !ok {
+			return state, fmt.Errorf("cannot load environments of service %s", service.Name)
+		}
+		for _, environment := range environments {
+			//Used space of volumes is a stat too
+			for index := range environment.Volumes {
+				environment.Volumes[index].Used = 0
+			}
+			state.Environments = append(state.Environments, environment)
+		}
+	}
+
+	return state, nil
+}
+
+func importCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, commandsUsage)
+		return 2
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot read file:", err)
+		return 1
+	}
+
+	var state LighthouseState
+	if err := json.Unmarshal(data, &state); err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot parse file:", err)
+		return 1
+	}
+
+	current, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load lighthouse state:", err)
+		return 1
+	}
+
+	//Dry run: print what is going to be created
+	plan := planImport(state, current)
+	for _, line := range plan.log {
+		fmt.Println(line)
+	}
+
+	if len(plan.projects) == 0 && len(plan.services) == 0 && len(plan.environments) == 0 {
+		fmt.Println("\nNothing to import")
+		return 0
+	}
+
+	if !YesNoPrompt("\nApply these changes? [y/N]", false) {
+		fmt.Println("Import cancelled, nothing has been changed")
+		return 0
+	}
+
+	if err := askRegistryPasswords(plan.services); err != nil {
+		fmt.Println("Import cancelled, nothing has been changed")
+		return 0
+	}
+
+	return applyImport(plan)
+}
+
+type importPlan struct {
+	log             []string
+	projects        []Project
+	services        []Service
+	serviceProjects map[string]string //service name -> project name
+	environments    []importEnvironment
+}
+
+type importEnvironment struct {
+	environment Environment
+	serviceName string
+}
+
+func planImport(state LighthouseState, current LighthouseState) importPlan {
+	plan := importPlan{serviceProjects: map[string]string{}}
+
+	//Project IDs are remapped by name as well
+	exportedProjectNames := map[string]string{}
+	for _, project := range state.Projects {
+		exportedProjectNames[project.Id] = project.Name
+	}
+	currentProjectIds := map[string]string{}
+	for _, project := range current.Projects {
+		currentProjectIds[project.Name] = project.Id
+	}
+	for _, project := range state.Projects {
+		if _, exists := currentProjectIds[project.Name]; !exists {
+			plan.log = append(plan.log, "+ project "+project.Name)
+			plan.projects = append(plan.projects, project)
+		}
+	}
+
+	//Machine IDs differ between lighthouses, so they are remapped by name
+	exportedMachineNames := map[string]string{}
+	for _, machine := range state.Machines {
+		exportedMachineNames[machine.Id] = machine.Name
+	}
+	currentMachineIds := map[string]string{}
+	for _, machine := range current.Machines {
+		currentMachineIds[machine.Name] = machine.Id
+	}
+
+	currentServiceIds := map[string]string{}
+	for _, service := range current.Services {
+		currentServiceIds[service.Name] = service.Id
+	}
+
+	for _, service := range state.Services {
+		serviceId, exists := currentServiceIds[service.Name]
+		if isDatabase(service) {
+			//Databases are provisioned by the lighthouse, their data and credentials aren't exported
+			if !exists {
+				plan.log = append(plan.log, "! database "+service.Name+" is skipped, add it again with "+MENU_ADD_DATABASE)
+			}
+			continue
+		}
+		if exists {
+			plan.log = append(plan.log, "Service "+service.Name+" already exists, skipping")
+		} else {
+			plan.log = append(plan.log, "+ service "+service.Name+" ("+serviceSource(service)+")")
+			if needsRegistryPassword(service) {
+				plan.log = append(plan.log, "  Registry password of "+service.Image.RegistryUsername+" isn't exported, you'll be asked for it")
+			}
+
+			previewMachineIds := []string{}
+			for _, machineId := range service.PreviewMachineIds {
+				if currentMachineId, ok := currentMachineIds[exportedMachineNames[machineId]]; ok {
+					previewMachineIds = append(previewMachineIds, currentMachineId)
+				}
+			}
+			service.PreviewMachineIds = previewMachineIds
+
+			plan.services = append(plan.services, service)
+			plan.serviceProjects[service.Name] = exportedProjectNames[service.ProjectId]
+		}
+
+		for _, environment := range state.Environments {
+			if environment.ServiceId != service.Id {
+				continue
+			}
+
+			if environment.IsPreview {
+				plan.log = append(plan.log, "  Preview environment "+environment.Name+" is skipped, it will be created again on the next push")
+				continue
+			}
+
+			if exists && environmentExists(current.Environments, serviceId, environment.Name) {
+				plan.log = append(plan.log, "  Environment "+environment.Name+" already exists, skipping")
+				continue
+			}
+
+			machineIds := []string{}
+			machineNames := []string{}
+			for _, machineId := range environment.MachineIds {
+				machineName := exportedMachineNames[machineId]
+				if currentMachineId, ok := currentMachineIds[machineName]; ok {
+					machineIds = append(machineIds, currentMachineId)
+					machineNames = append(machineNames, machineName)
+				} else {
+					plan.log = append(plan.log, "  ! machine "+machineName+" ("+machineId+") not found on this lighthouse, it won't be used by "+environment.Name)
+				}
+			}
+
+			environment.Id = ""
+			environment.ServiceId = serviceId
+			environment.MachineIds = machineIds
+			environment.LastDeploymentStatus = ""
+
+			plan.log = append(plan.log, "  + environment "+environment.Name+" (branch "+environment.Branch+", machines: "+strings.Join(machineNames, ", ")+")")
+			plan.environments = append(plan.environments, importEnvironment{environment: environment, serviceName: service.Name})
+		}
+	}
+
+	return plan
+}
+
+func needsRegistryPassword(service Service) bool {
+	return isImageService(service) && service.Image.RegistryUsername != "" && service.Image.RegistryPassword == ""
+}
+
+// registryPasswordPrompt asks for the registry password of an imported service
+var registryPasswordPrompt = func(service Service) (string, error) {
+	var password string
+	err := huh.NewInput().
+		Title("Registry Password or Token of " + service.Image.RegistryUsername).
+		Description("Service " + service.Name + " pulls " + imageReference(service.Image)).
+		EchoMode(huh.EchoModePassword).
+		Value(&password).
+		Validate(func(str string) error {
+			if str == "" {
+				return errors.New("enter a password or an access token of " + service.Image.RegistryUsername)
+			}
+			return nil
+		}).
+		Run()
+	return password, err
+}
+
+// askRegistryPasswords fills registry passwords of services created by the import, exports don't contain them
+func askRegistryPasswords(services []Service) error {
+	for index := range services {
+		if !needsRegistryPassword(services[index]) {
+			continue
+		}
+		password, err := registryPasswordPrompt(services[index])
+		if err != nil {
+			return err
+		}
+		services[index].Image.RegistryPassword = password
+	}
+	return nil
+}
+
+func environmentExists(environments []Environment, serviceId string, name string) bool {
+	for _, environment := range environments {
+		if environment.ServiceId == serviceId && environment.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func applyImport(plan importPlan) int {
+	failed := false
+
+	projectIds := map[string]string{}
+	if projects, ok := getProjects().(ProjectsMsg); ok {
+		for _, project := range projects {
+			projectIds[project.Name] = project.Id
+		}
+	}
+
+	for _, project := range plan.projects {
+		newProject := postProject(project.Name)
+		if newProject.Id == "" {
+			fmt.Fprintln(os.Stderr, "Cannot create project", project.Name)
+			failed = true
+			continue
+		}
+		projectIds[project.Name] = newProject.Id
+		fmt.Println("Created project", project.Name)
+	}
+
+	serviceIds := map[string]string{}
+
+	services, ok := getServices().(ServicesMsg)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Cannot load services")
+		return 1
+	}
+	for _, service := range services {
+		serviceIds[service.Name] = service.Id
+	}
+
+	for _, service := range plan.services {
+		projectId := ""
+		if projectName := plan.serviceProjects[service.Name]; projectName != "" {
+			projectId = projectIds[projectName]
+		}
+
+		service.ProjectId = projectId
+		newService := postService(service)
+		if newService.Id == "" {
+			fmt.Fprintln(os.Stderr, "Cannot create service", service.Name)
+			failed = true
+			continue
+		}
+		serviceIds[service.Name] = newService.Id
+		fmt.Println("Created service", service.Name)
+
+		if service.PreviewEnabled {
+			newService.PreviewEnabled = true
+			newService.PreviewBranchPattern = service.PreviewBranchPattern
+			newService.PreviewDomainTemplate = service.PreviewDomainTemplate
+			newService.PreviewPort = service.PreviewPort
+			newService.PreviewMachineIds = service.PreviewMachineIds
+			if updateService(newService).Id == "" {
+				fmt.Fprintln(os.Stderr, "Cannot set up preview environments of service", service.Name)
+				failed = true
+			}
+		}
+	}
+
+	for _, planned := range plan.environments {
+		environment := planned.environment
+		environment.ServiceId = serviceIds[planned.serviceName]
+		if environment.ServiceId == "" {
+			fmt.Fprintln(os.Stderr, "Skipping environment", environment.Name, "because service", planned.serviceName, "wasn't created")
+			failed = true
+			continue
+		}
+
+		newEnvironment, _ := postEnvironment(environment)().(NewEnvironmentAddedMsg)
+		if newEnvironment.Id == "" {
+			fmt.Fprintln(os.Stderr, "Cannot create environment", environment.Name)
+			failed = true
+			continue
+		}
+		fmt.Println("Created environment", environment.Name, "of service", planned.serviceName)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func envDeployCommand(args []string) int {
+	flags := flag.NewFlagSet("env deploy", flag.ContinueOnError)
+	wait := flags.Bool("wait", false, "wait until the deployment succeeds or fails, deployments queued by a freeze window fail")
+	timeout := flags.Duration("timeout", 10*time.Minute, "how long to wait for the deployment")
+	confirm := flags.String("confirm", "", "confirmation phrase required by protected environments")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: turbocloud env deploy <environment> [--wait] [--timeout 10m] [--confirm \"deploy <name>\"]")
+		return 2
+	}
+
+	state, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load lighthouse state:", err)
+		return 1
+	}
+
+	environment, err := findEnvironment(state, positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if environment.Protected && *confirm != "deploy "+environment.Name {
+		fmt.Fprintf(os.Stderr, "%s is protected, pass --confirm \"deploy %s\" to deploy it\n", environment.Name, environment.Name)
+		return 1
+	}
+
+	//A redeploy of the same commit can finish between two polls, the new deployment is told apart by its ID
+	previousDeploymentId, err := getPreviousDeploymentId(environment.Id)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot deploy "+environment.Name+",", err)
+		return 1
+	}
+
+	queuedAt, err := deployOutsideFreeze(environment, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, deploymentErrorHint(err))
+		return 1
+	}
+	if !queuedAt.IsZero() {
+		//There is nothing to wait for until the freeze window ends, with --wait the caller expects a deployed environment
+		if *wait {
+			fmt.Fprintln(os.Stderr, queuedDeploymentHint(environment, queuedAt), "It hasn't been deployed yet.")
+			return 1
+		}
+		fmt.Println(queuedDeploymentHint(environment, queuedAt))
+		return 0
+	}
+	fmt.Println("Deployment of", environment.Name, "is scheduled")
+
+	if !*wait {
+		return 0
+	}
+
+	status, err := waitForDeployment(environment, previousDeploymentId, *timeout, func(status string, elapsed time.Duration) {
+		fmt.Printf("[%s] %s\n", elapsed.Round(time.Second), status)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if status != DEPLOYMENT_STATUS_DEPLOYED {
+		fmt.Fprintln(os.Stderr, "Deployment of", environment.Name, "finished with status", deploymentStatusLabel(status))
+		return 1
+	}
+
+	fmt.Println("Deployment of", environment.Name, "succeeded")
+	return 0
+}
+
+// waitForDeployment polls the latest deployment of the environment until a deployment other than
+// previousDeploymentId finishes. Statuses are reported through progress when they change.
+func waitForDeployment(environment Environment, previousDeploymentId string, timeout time.Duration, progress func(status string, elapsed time.Duration)) (string, error) {
+	start := time.Now()
+	lastStatus := ""
+
+	for time.Since(start) < timeout {
+		//The previous deployment is returned until the new deployment starts
+		deployment, err := getDeployment(environment.Id)
+		if err == nil && deployment.Id != "" && deployment.Id != previousDeploymentId {
+			if deployment.Status != lastStatus {
+				progress(deployment.Status, time.Since(start))
+				lastStatus = deployment.Status
+			}
+			if !isDeploymentInProgress(deployment.Status) {
+				return deployment.Status, nil
+			}
+		}
+
+		time.Sleep(2 * time.Second)
+	}
+
+	return lastStatus, fmt.Errorf("deployment of %s didn't finish in %s", environment.Name, timeout)
+}
+
+func getEnvironmentById(serviceId string, environmentId string) (Environment, bool) {
+	environments, ok := getEnvironments(serviceId).(EnvironmentsMsg)
+	if !ok {
+		return Environment{}, false
+	}
+
+	for _, environment := range environments {
+		if environment.Id == environmentId {
+			return environment, true
+		}
+	}
+	return Environment{}, false
+}
+
+// envStatusCommand prints the deployment and the health of the environment,
+// it exits with 1 if the environment is unhealthy
+func envStatusCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: turbocloud env status <environment>")
+		return 2
+	}
+
+	state, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load lighthouse state:", err)
+		return 1
+	}
+
+	environment, err := findEnvironment(state, args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	deployment := deploymentStatusLabel(environment.LastDeploymentStatus)
+	if environment.LastDeploymentCommit != "" {
+		deployment += " " + shortCommit(environment.LastDeploymentCommit)
+	}
+	health := environment.Health
+	if health == "" {
+		health = "unknown"
+	}
+	if environment.HealthCheck.Path == "" {
+		health = "not checked"
+	}
+	if environment.HealthMessage != "" {
+		health += ", " + environment.HealthMessage
+	}
+	autoRollback := "off"
+	if environment.AutoRollback {
+		autoRollback = "on"
+	}
+
+	fmt.Println("Environment:   ", environment.Name)
+	fmt.Println("Branch:        ", environment.Branch)
+	fmt.Println("Deployment:    ", deployment)
+	fmt.Println("Health:        ", health)
+	fmt.Println("Health check:  ", healthCheckDescription(environment.HealthCheck))
+	fmt.Println("Auto rollback: ", autoRollback)
+
+	if environment.Health == HEALTH_STATUS_UNHEALTHY {
+		return 1
+	}
+	return 0
+}
+
+func envScheduleCommand(args []string) int {
+	flags := flag.NewFlagSet("env schedule", flag.ContinueOnError)
+	confirm := flags.String("confirm", "", "confirmation phrase required by protected environments")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: turbocloud env schedule <environment> \"2026-10-20 18:00\" [--confirm \"deploy <name>\"]")
+		return 2
+	}
+
+	state, err := getLighthouseState()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load lighthouse state:", err)
+		return 1
+	}
+
+	environment, err := findEnvironment(state, positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if environment.Protected && *confirm != "deploy "+environment.Name {
+		fmt.Fprintf(os.Stderr, "%s is protected, pass --confirm \"deploy %s\" to deploy it\n", environment.Name, environment.Name)
+		return 1
+	}
+
+	scheduledAt, err := parseScheduleTime(environment, positional[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot schedule deployment of", environment.Name+":", err)
+		return 1
+	}
+
+	if !postScheduledDeployment(environment.Id, "", scheduledAt) {
+		fmt.Fprintln(os.Stderr, "Cannot schedule deployment of", environment.Name)
+		return 1
+	}
+	fmt.Println("Deployment of", environment.Name, "is scheduled for", formatScheduleTime(scheduledAt))
+	return 0
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// useLighthouse points API calls to a stand-in lighthouse for the duration of the test
+func useLighthouse(t *testing.T, handler http.Handler) {
+	server := httptest.NewServer(handler)
+	previousBaseUrl := baseUrl
+	baseUrl = server.URL + "/"
+	t.Cleanup(func() {
+		baseUrl = previousBaseUrl
+		server.Close()
+	})
+}
+
+// A redeploy of the same commit that finishes before the first poll isn't missed
+func TestWaitForDeploymentSeesFastRedeploy(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/environment/e1/deployment" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(Deployment{Id: "d2", EnvironmentId: "e1", Commit: "abc", Status: DEPLOYMENT_STATUS_DEPLOYED})
+	}))
+
+	statuses := []string{}
+	status, err := waitForDeployment(Environment{Id: "e1", LastDeploymentCommit: "abc"}, "d1", time.Second, func(status string, elapsed time.Duration) {
+		statuses = append(statuses, status)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != DEPLOYMENT_STATUS_DEPLOYED || len(statuses) != 1 {
+		t.Errorf("status = %s, reported %q, want deployed once", status, statuses)
+	}
+}
+
+// The previous deployment isn't reported as the result of the new one
+func TestWaitForDeploymentIgnoresPreviousDeployment(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Deployment{Id: "d1", EnvironmentId: "e1", Status: DEPLOYMENT_STATUS_DEPLOYED})
+	}))
+
+	_, err := waitForDeployment(Environment{Id: "e1", Name: "prod"}, "d1", 10*time.Millisecond, func(string, time.Duration) {})
+	if err == nil {
+		t.Error("waitForDeployment() returned the previous deployment, want a timeout")
+	}
+}
+
+func TestDeployEnvironmentAcceptsAnySuccessStatus(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted} {
+		useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCode)
+		}))
+		if !deployEnvironment("e1") {
+			t.Errorf("deployEnvironment() = false for %d", statusCode)
+		}
+	}
+
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	if deployEnvironment("e1") {
+		t.Error("deployEnvironment() = true for 409")
+	}
+}
+
+// A deployment queued by a freeze window hasn't deployed anything, --wait must not report success
+func TestEnvDeployWaitFailsWhenQueued(t *testing.T) {
+	now := time.Now().UTC()
+	environment := Environment{
+		Id:            "e1",
+		Name:          "prod",
+		ServiceId:     "s1",
+		FreezeMode:    FREEZE_MODE_QUEUE,
+		FreezeWindows: []FreezeWindow{{Start: now.Add(-time.Hour).Format(SCHEDULE_TIME_LAYOUT), End: now.Add(time.Hour).Format(SCHEDULE_TIME_LAYOUT)}},
+	}
+	queued := 0
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/machine", "/machine/stats", "/project":
+			w.Write([]byte("[]"))
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web"}})
+		case "/service/s1/environment":
+			json.NewEncoder(w).Encode([]Environment{environment})
+		case "/deploy/scheduled":
+			queued++
+		case "/environment/e1/deployment":
+			json.NewEncoder(w).Encode(Deployment{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	if code := envDeployCommand([]string{"prod", "--wait"}); code != 1 {
+		t.Errorf("env deploy --wait = %d, want 1", code)
+	}
+	if code := envDeployCommand([]string{"prod"}); code != 0 {
+		t.Errorf("env deploy = %d, want 0", code)
+	}
+	if queued != 2 {
+		t.Errorf("deployments queued = %d, want 2", queued)
+	}
+}
+
+// A failed lookup of the current deployment doesn't let --wait report the previous deployment
+func TestEnvDeployFailsWithoutPreviousDeployment(t *testing.T) {
+	deployed := 0
+	deploymentStatusCode := http.StatusInternalServerError
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/machine", "/machine/stats", "/project":
+			w.Write([]byte("[]"))
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web"}})
+		case "/service/s1/environment":
+			json.NewEncoder(w).Encode([]Environment{{Id: "e1", Name: "prod", ServiceId: "s1"}})
+		case "/deploy/environment/e1":
+			deployed++
+		case "/environment/e1/deployment":
+			w.WriteHeader(deploymentStatusCode)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	if code := envDeployCommand([]string{"prod", "--wait"}); code != 1 || deployed != 0 {
+		t.Errorf("env deploy --wait = %d with %d deployments, want 1 without deploying", code, deployed)
+	}
+
+	//Environments that were never deployed have no previous deployment
+	deploymentStatusCode = http.StatusNotFound
+	if code := envDeployCommand([]string{"prod"}); code != 0 || deployed != 1 {
+		t.Errorf("env deploy = %d with %d deployments, want 0 after deploying", code, deployed)
+	}
+}
+
+// Exported state is configuration only, usage stats would show up as changes in every diff
+func TestGetLighthouseStateDropsStatsAndCredentials(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/machine":
+			json.NewEncoder(w).Encode([]Machine{{Id: "m1", Name: "alpha", CPUUsage: "12", VolumesUsage: "123", JoinURL: "https://lighthouse/join/x"}})
+		case "/machine/stats", "/project":
+			w.Write([]byte("[]"))
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web", Source: SERVICE_SOURCE_IMAGE, Image: ContainerImage{Reference: "ghcr.io/acme/web", RegistryUsername: "ci", RegistryPassword: "token"}}})
+		case "/service/s1/environment":
+			json.NewEncoder(w).Encode([]Environment{{Id: "e1", Name: "prod", Volumes: []Volume{{Name: "uploads", MountPath: "/data", Used: 512}}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	state, err := getLighthouseState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine := state.Machines[0]
+	if machine.CPUUsage != "" || machine.VolumesUsage != "" || machine.JoinURL != "" {
+		t.Errorf("machine stats are exported: %+v", machine)
+	}
+	volume := state.Environments[0].Volumes[0]
+	if volume.Used != 0 || volume.MountPath != "/data" {
+		t.Errorf("volume = %+v, want the mount path without the used space", volume)
+	}
+	if image := state.Services[0].Image; image.RegistryPassword != "" || image.RegistryUsername != "ci" {
+		t.Errorf("image = %+v, want the username without the password", image)
+	}
+}
+
+// Imported image services get the registry password typed again, the lighthouse receives it
+func TestImportAsksForRegistryPasswords(t *testing.T) {
+	previousPrompt := registryPasswordPrompt
+	t.Cleanup(func() { registryPasswordPrompt = previousPrompt })
+	asked := []string{}
+	registryPasswordPrompt = func(service Service) (string, error) {
+		asked = append(asked, service.Name)
+		return "new-token", nil
+	}
+
+	var posted Service
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/service" && r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&posted)
+			posted.Id = "s2"
+			json.NewEncoder(w).Encode(posted)
+		case r.URL.Path == "/service", r.URL.Path == "/project":
+			w.Write([]byte("[]"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	state := LighthouseState{Services: []Service{
+		{Id: "s1", Name: "web", Source: SERVICE_SOURCE_IMAGE, Image: ContainerImage{Reference: "ghcr.io/acme/web", Tag: "latest", RegistryUsername: "ci"}},
+		{Id: "s2", Name: "api", Source: SERVICE_SOURCE_IMAGE, Image: ContainerImage{Reference: "nginx", Tag: "latest"}},
+	}}
+	plan := planImport(state, LighthouseState{})
+	if err := askRegistryPasswords(plan.services); err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 1 || asked[0] != "web" {
+		t.Errorf("asked for passwords of %q, want web only", asked)
+	}
+
+	plan.services = plan.services[:1]
+	if applyImport(plan) != 0 {
+		t.Fatal("applyImport() failed")
+	}
+	if posted.Image.RegistryUsername != "ci" || posted.Image.RegistryPassword != "new-token" {
+		t.Errorf("lighthouse received image %+v, want the typed password", posted.Image)
+	}
+}
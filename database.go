@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const MENU_ADD_DATABASE = "Add Database"
+const MENU_LINK_ENVIRONMENTS = "Link Environments"
+
+// databaseTemplate describes a database the lighthouse can provision,
+// variables are added to linked environments with connection credentials
+type databaseTemplate struct {
+	engine    string
+	label     string
+	versions  []string
+	variables []string
+}
+
+var databaseTemplates = []databaseTemplate{
+	{
+		engine:    DATABASE_ENGINE_POSTGRES,
+		label:     "PostgreSQL",
+		versions:  []string{"17", "16", "15"},
+		variables: []string{"DATABASE_URL", "PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE"},
+	},
+	{
+		engine:    DATABASE_ENGINE_REDIS,
+		label:     "Redis",
+		versions:  []string{"7.4", "7.2"},
+		variables: []string{"REDIS_URL"},
+	},
+}
+
+var (
+	newDatabaseName           string
+	newDatabaseTemplate       string //engine and version, for example postgres:17
+	newDatabaseMachines       []string
+	newDatabaseEnvironmentIds []string
+	newDatabaseProjectId      string
+	newDatabaseIsAdd          bool
+)
+
+var (
+	linkedEnvironmentIds []string
+	linkIsSave           bool
+)
+
+type NewDatabaseMsg int
+
+func newDatabaseMsg() tea.Msg {
+	var msg NewDatabaseMsg
+	return msg
+}
+
+type NewDatabaseAddedMsg Service
+
+func isDatabase(service Service) bool {
+	return service.DatabaseEngine != ""
+}
+
+func databaseTemplateByEngine(engine string) databaseTemplate {
+	for _, template := range databaseTemplates {
+		if template.engine == engine {
+			return template
+		}
+	}
+	return databaseTemplate{engine: engine, label: engine}
+}
+
+// databaseSource describes the database in the Services table, for example "PostgreSQL 17"
+func databaseSource(service Service) string {
+	return strings.TrimSpace(databaseTemplateByEngine(service.DatabaseEngine).label + " " + service.DatabaseVersion)
+}
+
+// environmentOptions returns environments of all services built from Git, database services have no environments
+func environmentOptions(services []Service) []huh.Option[string] {
+	options := []huh.Option[string]{}
+	for _, service := range services {
+		if isDatabase(service) {
+			continue
+		}
+		environments, ok := getEnvironments(service.Id).(EnvironmentsMsg)
+		if !ok {
+			continue
+		}
+		for _, environment := range environments {
+			options = append(options, huh.NewOption(service.Name+" / "+environment.Name, environment.Id))
+		}
+	}
+	return options
+}
+
+func databaseVariablesDescription(engine string) string {
+	return "Connection credentials are added to linked environments as " + strings.Join(databaseTemplateByEngine(engine).variables, ", ")
+}
+
+// createDatabaseForm asks for the database template, the machines that run it and environments that use it
+func createDatabaseForm(m *model) {
+
+	newDatabaseName = ""
+	newDatabaseTemplate = databaseTemplates[0].engine + ":" + databaseTemplates[0].versions[0]
+	newDatabaseMachines = newDatabaseMachines[:0]
+	newDatabaseEnvironmentIds = newDatabaseEnvironmentIds[:0]
+	newDatabaseProjectId = m.selectedProject.Id
+	newDatabaseIsAdd = true
+
+	templateOptions := []huh.Option[string]{}
+	for _, template := range databaseTemplates {
+		for _, version := range template.versions {
+			templateOptions = append(templateOptions, huh.NewOption(template.label+" "+version, template.engine+":"+version))
+		}
+	}
+
+	machineOptions, _ := getMachineOptions()
+
+	services, _ := getServices().(ServicesMsg)
+
+	databaseFields := []huh.Field{
+		huh.NewInput().
+			Title("Database Name").
+			Value(&newDatabaseName).
+			Validate(func(str string) error {
+				if strings.TrimSpace(str) == "" {
+					return errors.New("enter a name")
+				}
+				return nil
+			}),
+		huh.NewSelect[string]().
+			Title("Database").
+			Options(templateOptions...).
+			Value(&newDatabaseTemplate),
+		huh.NewMultiSelect[string]().
+			Title("Choose Servers to Run the Database").
+			Description("Each server runs its own copy, data is kept across restarts").
+			Options(machineOptions...).
+			Value(&newDatabaseMachines).
+			Validate(func(machineIds []string) error {
+				if len(machineIds) == 0 {
+					return errors.New("choose at least one server")
+				}
+				return nil
+			}),
+	}
+
+	if options := environmentOptions(services); len(options) > 0 {
+		databaseFields = append(databaseFields, huh.NewMultiSelect[string]().
+			Title("Link Environments").
+			DescriptionFunc(func() string {
+				engine, _, _ := strings.Cut(newDatabaseTemplate, ":")
+				return databaseVariablesDescription(engine)
+			}, &newDatabaseTemplate).
+			Options(options...).
+			Value(&newDatabaseEnvironmentIds))
+	}
+
+	if projectOptions := getProjectOptions(); projectOptions != nil {
+		databaseFields = append(databaseFields, huh.NewSelect[string]().
+			Title("Project").
+			Options(projectOptions...).
+			Value(&newDatabaseProjectId))
+	}
+
+	databaseFields = append(databaseFields, huh.NewConfirm().
+		Key("done").
+		Title("Add a new database?").
+		Affirmative("Add").
+		Negative("Cancel").
+		Value(&newDatabaseIsAdd))
+
+	m.newDatabaseForm = huh.NewForm(
+		huh.NewGroup(databaseFields...),
+	).WithHeight(m.screenHeight - 14)
+
+	m.newDatabaseForm.Init()
+}
+
+// addDatabase sends the database typed in the Add Database form, the lighthouse provisions it in the background
+func addDatabase() tea.Msg {
+	engine, version, _ := strings.Cut(newDatabaseTemplate, ":")
+	return NewDatabaseAddedMsg(postDatabase(Service{
+		Name:                 strings.TrimSpace(newDatabaseName),
+		ProjectId:            newDatabaseProjectId,
+		DatabaseEngine:       engine,
+		DatabaseVersion:      version,
+		MachineIds:           newDatabaseMachines,
+		LinkedEnvironmentIds: newDatabaseEnvironmentIds,
+	}))
+}
+
+// createDatabaseMenu shows actions of the database selected in the Services table
+func createDatabaseMenu(m *model) {
+	databaseMenuItems := []list.Item{
+		item{title: MENU_BACK, description: ""},
+		item{title: MENU_LINK_ENVIRONMENTS, description: ""},
+		item{title: MENU_BACKUPS, description: ""},
+		item{title: MENU_DELETE, description: ""},
+	}
+
+	m.databaseMenu = list.New(databaseMenuItems, envMenuItemDelegate{}, defaultWidth, listHeight)
+	m.databaseMenu.SetShowStatusBar(false)
+	m.databaseMenu.SetFilteringEnabled(false)
+	m.databaseMenu.SetShowHelp(false)
+	m.databaseMenu.SetShowTitle(false)
+
+	v, _ := listStyle.GetFrameSize()
+	m.databaseMenu.SetSize(m.screenWidth-2*v, m.screenHeight-listTopHintHeght)
+}
+
+// createLinkEnvironmentsForm asks which environments get connection credentials of the database
+func createLinkEnvironmentsForm(m *model) {
+
+	linkedEnvironmentIds = append([]string{}, m.selectedService.LinkedEnvironmentIds...)
+	linkIsSave = true
+
+	m.linkEnvironmentsForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Link Environments").
+				Description(databaseVariablesDescription(m.selectedService.DatabaseEngine)+", unlinked environments lose them on the next deployment").
+				Options(environmentOptions(m.services)...).
+				Value(&linkedEnvironmentIds),
+			huh.NewConfirm().
+				Key("done").
+				Title("Save linked environments?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&linkIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.linkEnvironmentsForm.Init()
+}
+
+// databaseHealthCell renders the Health column of the Services table
+func databaseHealthCell(service Service) string {
+	switch {
+	case !isDatabase(service):
+		return ""
+	case service.Health == HEALTH_STATUS_HEALTHY:
+		return deploymentDeployedStyle.Render("● " + service.Health)
+	case service.Health == HEALTH_STATUS_UNHEALTHY:
+		return deploymentFailedStyle.Render("● " + service.Health)
+	}
+	return "○ provisioning"
+}
+
+// backupCell renders the Backup column of the Services table
+func backupCell(service Service) string {
+	switch {
+	case !isDatabase(service):
+		return ""
+	case service.BackupStatus == BACKUP_STATUS_OK:
+		return deploymentDeployedStyle.Render("✓ " + formatScheduleTime(service.LastBackupAt))
+	case service.BackupStatus == BACKUP_STATUS_FAILED:
+		return deploymentFailedStyle.Render("✗ failed")
+	}
+	return "no backups"
+}
+
+// linkedEnvironmentNames returns names of environments linked to the database, for example "web / staging"
+func linkedEnvironmentNames(m model) []string {
+	names := []string{}
+	for _, option := range environmentOptions(m.services) {
+		if slices.Contains(m.selectedService.LinkedEnvironmentIds, option.Value) {
+			names = append(names, option.Key)
+		}
+	}
+	return names
+}
+
+// databaseDeleteImpactView lists environments that lose connection credentials with the database
+func databaseDeleteImpactView(m model) string {
+	var builder strings.Builder
+	builder.WriteString(" Data of this database will be deleted from servers.\n")
+
+	if len(m.databaseLinkedNames) > 0 {
+		builder.WriteString(" These environments will lose " + strings.Join(databaseTemplateByEngine(m.selectedService.DatabaseEngine).variables, ", ") + ":\n\n")
+		for _, name := range m.databaseLinkedNames {
+			builder.WriteString(" • " + name + "\n")
+		}
+	}
+	return builder.String()
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -33,6 +35,12 @@ func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 					return getServices
 				} else if title == "Add Service" {
 					return newServiceMsg
+				} else if title == MENU_ADD_DATABASE {
+					return newDatabaseMsg
+				} else if title == MENU_BACKUP_STORAGE {
+					return newBackupTargetMsg
+				} else if strings.HasPrefix(title, MENU_PROJECT_PREFIX) {
+					return getProjects
 				} else if title == "Docs" {
 					openbrowser("https://turbocloud.dev/docs")
 					return nil
@@ -58,12 +66,16 @@ func newItemDelegate(keys *delegateKeyMap) list.DefaultDelegate {
 	return d
 }
 
+// newMachineMsg loads existing machines, names of new machines must be unique
 func newMachineMsg() tea.Msg {
 	var msg NewMachineMsg
+	msg.machines, _ = getMachines().(MachineMsg)
 	return msg
 }
 
-type NewMachineMsg int
+type NewMachineMsg struct {
+	machines []Machine
+}
 
 func newServiceMsg() tea.Msg {
 	var msg NewServiceMsg
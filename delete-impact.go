@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Machine types without which deployments break
+var criticalMachineTypes = []string{MACHINE_TYPE_BUILDER}
+
+type MachineDeleteImpactMsg struct {
+	machine      Machine
+	environments []machineEnvironment
+	machines     []Machine
+	err          error //the impact is unknown, the delete is blocked
+}
+
+func machineDeleteImpactMsg(machineId string) tea.Cmd {
+	return func() tea.Msg {
+		var msg MachineDeleteImpactMsg
+		machines, ok := getMachines().(MachineMsg)
+		if !ok {
+			msg.err = errors.New("machines can't be loaded")
+		}
+		msg.machines = machines
+		msg.machine = Machine{Id: machineId}
+		for _, machine := range msg.machines {
+			if machine.Id == machineId {
+				msg.machine = machine
+			}
+		}
+		environments, err := getMachineEnvironments([]string{machineId})
+		if err != nil && msg.err == nil {
+			msg.err = err
+		}
+		msg.environments = environments
+		return msg
+	}
+}
+
+// soleMachineTypes returns critical types of the machine that no other machine has
+func soleMachineTypes(machine Machine, machines []Machine) []string {
+	types := []string{}
+	for _, machineType := range criticalMachineTypes {
+		if !slices.Contains(machine.Types, machineType) {
+			continue
+		}
+		other := slices.ContainsFunc(machines, func(m Machine) bool {
+			return m.Id != machine.Id && slices.Contains(m.Types, machineType)
+		})
+		if !other {
+			types = append(types, machineType)
+		}
+	}
+	return types
+}
+
+// isMachineDeleteBlocked reports whether deleting the machine would leave no builder or its impact can't be checked
+func isMachineDeleteBlocked(msg MachineDeleteImpactMsg) bool {
+	return msg.err != nil || len(soleMachineTypes(msg.machine, msg.machines)) > 0
+}
+
+func machineDeleteImpactView(msg MachineDeleteImpactMsg) string {
+	if msg.err != nil {
+		return bulkResultErrorStyle.Render(" The machine can't be deleted, "+msg.err.Error()+" to check what depends on it. Try again later.") + "\n"
+	}
+
+	var builder strings.Builder
+
+	if len(msg.machine.Types) > 0 {
+		builder.WriteString(" Roles: " + strings.Join(msg.machine.Types, ", ") + "\n\n")
+	}
+	builder.WriteString(machineEnvironmentsView(msg.machine.Id, msg.environments, msg.machines))
+
+	if sole := soleMachineTypes(msg.machine, msg.machines); len(sole) > 0 {
+		builder.WriteString("\n" + bulkResultErrorStyle.Render(" This is the only machine of type '"+strings.Join(sole, "', '")+"', deployments can't work without it.\n Add the type to another machine in Edit / Details before deleting this machine.") + "\n")
+	}
+
+	return builder.String()
+}
+
+// serviceDeleteImpactView lists environments and domains removed with the service
+func serviceDeleteImpactView(m model) string {
+	if isDatabase(m.selectedService) {
+		return databaseDeleteImpactView(m)
+	}
+
+	environments := m.environments
+	if len(environments) == 0 {
+		return " This service has no environments.\n"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(" These environments will be deleted and their domains will stop serving traffic:\n\n")
+	for _, environment := range environments {
+		builder.WriteString(environmentDeleteLine(environment) + "\n")
+	}
+	return builder.String()
+}
+
+// environmentDeleteLine describes domains and volume data removed with the environment
+func environmentDeleteLine(environment Environment) string {
+	line := " • " + environment.Name
+	if environment.IsPreview {
+		line += " (preview)"
+	}
+	if len(environment.Domains) > 0 {
+		line += " - " + strings.Join(environment.Domains, ", ")
+	}
+	if len(environment.Volumes) > 0 {
+		line += " - data of volumes: " + volumesUsage(environment.Volumes)
+	}
+	return line
+}
+
+// bulkDeleteBlockedTypes returns critical types all machines of which are selected for deletion
+func bulkDeleteBlockedTypes(m model) []string {
+	types := []string{}
+	if m.bulkTarget != BULK_TARGET_MACHINES {
+		return types
+	}
+
+	for _, machineType := range criticalMachineTypes {
+		remaining := slices.ContainsFunc(m.machines, func(machine Machine) bool {
+			return !m.selectedMachineIds[machine.Id] && slices.Contains(machine.Types, machineType)
+		})
+		selected := slices.ContainsFunc(selectedMachines(m), func(machine Machine) bool {
+			return slices.Contains(machine.Types, machineType)
+		})
+		if selected && !remaining {
+			types = append(types, machineType)
+		}
+	}
+	return types
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// The machine delete is blocked when environments on the machine can't be checked
+func TestMachineDeleteImpactFailsClosed(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/machine":
+			json.NewEncoder(w).Encode([]Machine{{Id: "m1", Name: "alpha", Types: []string{MACHINE_TYPE_WORKLOAD}}, {Id: "m2", Name: "beta", Types: []string{MACHINE_TYPE_BUILDER}}})
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web"}})
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+
+	msg, ok := machineDeleteImpactMsg("m1")().(MachineDeleteImpactMsg)
+	if !ok {
+		t.Fatal("expected MachineDeleteImpactMsg")
+	}
+	if msg.err == nil || !isMachineDeleteBlocked(msg) {
+		t.Fatalf("delete isn't blocked, err = %v", msg.err)
+	}
+
+	drain, ok := drainMachineMsg(msg.machine)().(DrainMachineMsg)
+	if !ok || drain.err == nil {
+		t.Fatal("expected the drain to be blocked too")
+	}
+}
+
+func TestMachineDeleteImpactWithoutMachines(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{})
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+
+	msg := machineDeleteImpactMsg("m1")().(MachineDeleteImpactMsg)
+	if !isMachineDeleteBlocked(msg) {
+		t.Fatal("delete isn't blocked when machines can't be loaded")
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Basic ANSI colors keep escape sequences short enough for table cells
+var (
+	deploymentDeployedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	deploymentFailedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	toastStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFDF5")).
+			Background(lipgloss.Color("#5A56E0")).
+			Padding(0, 1)
+	toastPositionStyle = lipgloss.NewStyle().Padding(0, 4)
+)
+
+const toastDuration = 5 * time.Second
+
+// trackedDeployment is a deployment started from this CLI, it's polled until it finishes
+// even if the Environments screen isn't open
+type trackedDeployment struct {
+	environmentId   string
+	environmentName string
+	serviceId       string
+	initialCommit   string
+	startedAt       time.Time
+	status          string
+	started         bool
+}
+
+type DeploymentsPolledMsg struct {
+	serviceIds   []string //services with successfully loaded environments
+	environments []Environment
+}
+
+type ToastExpiredMsg time.Time
+
+// deploymentHasStarted reports whether the environment shows the new deployment and not the previous one
+func deploymentHasStarted(environment Environment, initialCommit string) bool {
+	return isDeploymentInProgress(environment.LastDeploymentStatus) || environment.LastDeploymentCommit != initialCommit
+}
+
+// trackDeployment starts polling the deployment of the environment
+func trackDeployment(m *model, environment Environment) tea.Cmd {
+	m.deployments[environment.Id] = &trackedDeployment{
+		environmentId:   environment.Id,
+		environmentName: environment.Name,
+		serviceId:       environment.ServiceId,
+		initialCommit:   environment.LastDeploymentCommit,
+		startedAt:       time.Now(),
+		status:          DEPLOYMENT_STATUS_SCHEDULED,
+	}
+
+	cmds := []tea.Cmd{startSpinner(m)}
+	if !m.deploymentsPolling {
+		m.deploymentsPolling = true
+		cmds = append(cmds, pollDeployments(m.deployments))
+	}
+	return tea.Batch(cmds...)
+}
+
+func pollDeployments(deployments map[string]*trackedDeployment) tea.Cmd {
+	serviceIds := []string{}
+	for _, deployment := range deployments {
+		serviceIds = append(serviceIds, deployment.serviceId)
+	}
+
+	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
+		var msg DeploymentsPolledMsg
+		polled := map[string]bool{}
+		for _, serviceId := range serviceIds {
+			if polled[serviceId] {
+				continue
+			}
+			polled[serviceId] = true
+			if environments, ok := getEnvironments(serviceId).(EnvironmentsMsg); ok {
+				msg.serviceIds = append(msg.serviceIds, serviceId)
+				msg.environments = append(msg.environments, environments...)
+			}
+		}
+		return msg
+	})
+}
+
+// updateDeployments applies polled statuses and shows a toast for finished deployments
+func updateDeployments(m *model, msg DeploymentsPolledMsg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	//Environments deleted during the deployment aren't polled anymore
+	for environmentId, deployment := range m.deployments {
+		if !slices.Contains(msg.serviceIds, deployment.serviceId) {
+			continue
+		}
+		if !slices.ContainsFunc(msg.environments, func(environment Environment) bool { return environment.Id == environmentId }) {
+			delete(m.deployments, environmentId)
+		}
+	}
+
+	for _, environment := range msg.environments {
+		deployment, ok := m.deployments[environment.Id]
+		if !ok {
+			continue
+		}
+
+		if deploymentHasStarted(environment, deployment.initialCommit) {
+			deployment.started = true
+			deployment.status = environment.LastDeploymentStatus
+		}
+
+		if deployment.started && !isDeploymentInProgress(environment.LastDeploymentStatus) {
+			delete(m.deployments, environment.Id)
+			cmds = append(cmds, showToast(m, "Deployment of "+deployment.environmentName+" "+deploymentStatusLabel(environment.LastDeploymentStatus)+" in "+formatElapsed(time.Since(deployment.startedAt))))
+		}
+	}
+
+	if len(m.deployments) > 0 {
+		cmds = append(cmds, pollDeployments(m.deployments))
+	} else {
+		m.deploymentsPolling = false
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func showToast(m *model, text string) tea.Cmd {
+	m.toast = text
+	m.toastUntil = time.Now().Add(toastDuration)
+	resizeMainMenu(m)
+
+	return tea.Tick(toastDuration, func(t time.Time) tea.Msg {
+		return ToastExpiredMsg(t)
+	})
+}
+
+// resizeMainMenu leaves space for the toast below the main menu
+func resizeMainMenu(m *model) {
+	toastHeight := 0
+	if m.toast != "" {
+		toastHeight = 2
+	}
+
+	h, v := appStyle.GetFrameSize()
+	m.list.SetSize(m.screenWidth-h, m.screenHeight-v-toastHeight)
+}
+
+func toastView(m model) string {
+	if m.toast == "" {
+		return ""
+	}
+	return "\n" + toastPositionStyle.Render(toastStyle.Render(m.toast)) + "\n"
+}
+
+// startSpinner starts the spinner of in-flight deployments if it isn't running yet
+func startSpinner(m *model) tea.Cmd {
+	if m.spinnerRunning {
+		return nil
+	}
+	m.spinnerRunning = true
+	return m.spinner.Tick
+}
+
+// environmentForDeployment returns the environment shown in the Environments table
+func environmentForDeployment(m model, environmentId string) Environment {
+	for _, environment := range m.environments {
+		if environment.Id == environmentId {
+			return environment
+		}
+	}
+	return Environment{Id: environmentId, Name: m.selectedEnvironment.Name, ServiceId: m.selectedService.Id}
+}
+
+// isAnyDeploymentInProgress reports whether the spinner is needed
+func isAnyDeploymentInProgress(m model) bool {
+	if len(m.deployments) > 0 {
+		return true
+	}
+	if screenType != SCREEN_TYPE_ENVIRONMENTS {
+		return false
+	}
+	for _, environment := range m.environments {
+		if isDeploymentInProgress(environment.LastDeploymentStatus) {
+			return true
+		}
+	}
+	return false
+}
+
+// deploymentStatusCell renders the Status column of the Environments table
+func deploymentStatusCell(m model, environment Environment) string {
+	status := environment.LastDeploymentStatus
+
+	if deployment, ok := m.deployments[environment.Id]; ok {
+		return m.spinner.View() + deploymentStatusLabel(deployment.status) + " " + formatElapsed(time.Since(deployment.startedAt))
+	}
+
+	switch {
+	case isDeploymentInProgress(status):
+		return m.spinner.View() + status
+	case status == DEPLOYMENT_STATUS_DEPLOYED:
+		return deploymentDeployedStyle.Render("✓ " + status)
+	case status == DEPLOYMENT_STATUS_FAILED || status == DEPLOYMENT_STATUS_ABORTED:
+		return deploymentFailedStyle.Render("✗ " + status)
+	case status == DEPLOYMENT_STATUS_ROLLED_BACK:
+		return deploymentFailedStyle.Render("↺ " + deploymentStatusLabel(status))
+	}
+	return status
+}
+
+// deploymentProgressView is shown on the Deployment Scheduled screen
+func deploymentProgressView(m model, environmentId string) string {
+	deployment, ok := m.deployments[environmentId]
+	if !ok {
+		return "Deployment has finished, see the status in Environments"
+	}
+	return m.spinner.View() + deployment.status + " " + formatElapsed(time.Since(deployment.startedAt))
+}
+
+func formatElapsed(elapsed time.Duration) string {
+	elapsed = elapsed.Round(time.Second)
+	if elapsed < time.Minute {
+		return fmt.Sprintf("%ds", int(elapsed.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(elapsed.Minutes()), int(elapsed.Seconds())%60)
+}
+
+func newDeploymentSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot))
+}
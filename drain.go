@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const MENU_DRAIN = "Drain"
+const MENU_UNDRAIN = "Undrain"
+
+// Shown instead of the machine status while deployments still run on a drained machine and once they finished
+const MACHINE_STATUS_DRAINING = "Draining"
+const MACHINE_STATUS_DRAINED = "Drained"
+
+type DrainMachineMsg struct {
+	machine      Machine
+	environments []machineEnvironment
+	err          error //environments losing the machine are unknown, the drain is blocked
+}
+
+// DrainProgressMsg lists checked drained machines without running deployments
+type DrainProgressMsg struct {
+	drainedMachineIds []string
+}
+
+// machineStatus returns the status shown in the Machines table
+func machineStatus(m model, machine Machine) string {
+	if !machine.Drained {
+		return machine.Status
+	}
+	if m.drainedMachineIds[machine.Id] {
+		return MACHINE_STATUS_DRAINED
+	}
+	return MACHINE_STATUS_DRAINING
+}
+
+// drainProgressCmd checks drained machines that can still run deployments, a machine is done draining
+// once they finished and stays done because the lighthouse doesn't start new deployments on it
+func drainProgressCmd(m model) tea.Cmd {
+	machineIds := []string{}
+	for _, machine := range m.machines {
+		if machine.Drained && !m.drainedMachineIds[machine.Id] {
+			machineIds = append(machineIds, machine.Id)
+		}
+	}
+	if len(machineIds) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		var msg DrainProgressMsg
+		environments, err := getMachineEnvironments(machineIds)
+		if err != nil {
+			//Machines stay Draining, they are checked again with the next machine list
+			return nil
+		}
+		for _, machineId := range machineIds {
+			running := slices.ContainsFunc(environmentsOnMachine(environments, machineId), func(machineEnvironment machineEnvironment) bool {
+				return isDeploymentInProgress(machineEnvironment.environment.LastDeploymentStatus)
+			})
+			if !running {
+				msg.drainedMachineIds = append(msg.drainedMachineIds, machineId)
+			}
+		}
+		return msg
+	}
+}
+
+// updateDrainProgress marks machines as drained and tells when the machine drained from this screen is done
+func updateDrainProgress(m *model, msg DrainProgressMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, machineId := range msg.drainedMachineIds {
+		m.drainedMachineIds[machineId] = true
+		if machineId == m.drainMachine.machine.Id {
+			cmd = showToast(m, m.drainMachine.machine.Name+" is drained, it's safe to reboot or resize it")
+		}
+	}
+	refreshTableRows(m)
+	return cmd
+}
+
+func drainMachineMsg(machine Machine) tea.Cmd {
+	return func() tea.Msg {
+		environments, err := getMachineEnvironments([]string{machine.Id})
+		return DrainMachineMsg{machine: machine, environments: environments, err: err}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// A drained machine stays Draining while deployments still run on it
+func TestDrainProgress(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/service":
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web"}})
+		case "/service/s1/environment":
+			json.NewEncoder(w).Encode([]Environment{
+				{Id: "e1", Name: "staging", MachineIds: []string{"m1"}, LastDeploymentStatus: DEPLOYMENT_STATUS_BUILDING},
+				{Id: "e2", Name: "prod", MachineIds: []string{"m2"}, LastDeploymentStatus: DEPLOYMENT_STATUS_DEPLOYED},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	m := newModel()
+	m.machines = MachineMsg{{Id: "m1", Name: "alpha", Drained: true}, {Id: "m2", Name: "beta", Drained: true}, {Id: "m3", Name: "gamma", Status: "Online"}}
+	m.drainMachine.machine = m.machines[1]
+
+	msg, ok := drainProgressCmd(m)().(DrainProgressMsg)
+	if !ok || !slices.Equal(msg.drainedMachineIds, []string{"m2"}) {
+		t.Fatalf("drained machines = %v, want [m2]", msg.drainedMachineIds)
+	}
+
+	if updateDrainProgress(&m, msg) == nil {
+		t.Error("expected a toast for the machine drained from the Drain screen")
+	}
+	for _, test := range []struct {
+		machine Machine
+		status  string
+	}{
+		{m.machines[0], MACHINE_STATUS_DRAINING},
+		{m.machines[1], MACHINE_STATUS_DRAINED},
+		{m.machines[2], "Online"},
+	} {
+		if status := machineStatus(m, test.machine); status != test.status {
+			t.Errorf("status of %s = %q, want %q", test.machine.Name, status, test.status)
+		}
+	}
+
+	//Drained machines without running deployments aren't checked again
+	m.machines = m.machines[1:]
+	if drainProgressCmd(m) != nil {
+		t.Error("expected no check when all drained machines are done")
+	}
+}
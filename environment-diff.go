@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const MASKED_VALUE = "••••••"
+
+var (
+	diffHighlightStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#e07a5f", Dark: "#e07a5f"})
+	diffHeaderStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#bfbfbf"))
+)
+
+type environmentDiffLine struct {
+	field   string
+	a       string
+	b       string
+	differs bool
+}
+
+// diffEnvironments compares settings of two environments, machine IDs are shown as names
+func diffEnvironments(a Environment, b Environment, machines []Machine, showValues bool) []environmentDiffLine {
+	lines := []environmentDiffLine{}
+
+	add := func(field string, valueA string, valueB string) {
+		lines = append(lines, environmentDiffLine{field: field, a: valueA, b: valueB, differs: valueA != valueB})
+	}
+
+	add("Branch", a.Branch, b.Branch)
+	add("Git Tag", a.GitTag, b.GitTag)
+	add("Port", a.Port, b.Port)
+	add("Domains", strings.Join(a.Domains, ", "), strings.Join(b.Domains, ", "))
+	add("Machines", strings.Join(environmentMachineNames(a, machines), ", "), strings.Join(environmentMachineNames(b, machines), ", "))
+	add("Resources", resourcesDescription(a), resourcesDescription(b))
+	add("Last Commit", shortCommit(a.LastDeploymentCommit), shortCommit(b.LastDeploymentCommit))
+
+	//Environment variables are compared by name
+	variablesA := environmentVariablesMap(a.EnvironmentVariables)
+	variablesB := environmentVariablesMap(b.EnvironmentVariables)
+	names := []string{}
+	for name := range variablesA {
+		names = append(names, name)
+	}
+	for name := range variablesB {
+		if _, ok := variablesA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		valueA, okA := variablesA[name]
+		valueB, okB := variablesB[name]
+		line := environmentDiffLine{field: "$" + name, differs: okA != okB || valueA != valueB}
+		line.a = displayVariableValue(valueA, okA, showValues)
+		line.b = displayVariableValue(valueB, okB, showValues)
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func environmentMachineNames(environment Environment, machines []Machine) []string {
+	names := []string{}
+	for _, machineId := range environment.MachineIds {
+		name := machineId
+		for _, machine := range machines {
+			if machine.Id == machineId {
+				name = machine.Name
+			}
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func environmentVariablesMap(environmentVariables []EnvironmentVariable) map[string]string {
+	variables := map[string]string{}
+	for _, variable := range environmentVariables {
+		variables[variable.Name] = variable.Value
+	}
+	return variables
+}
+
+func displayVariableValue(value string, ok bool, showValues bool) string {
+	if !ok {
+		return "—"
+	}
+	if !showValues {
+		return MASKED_VALUE
+	}
+	return value
+}
+
+// formatEnvironmentDiff renders the diff as plain text, changed lines are marked with *
+func formatEnvironmentDiff(a Environment, b Environment, lines []environmentDiffLine) string {
+	var builder strings.Builder
+
+	builder.WriteString(diffHeaderStyle.Render(fmt.Sprintf("  %-16s %-32s %-32s", "", truncate(a.Name, 32), truncate(b.Name, 32))) + "\n")
+
+	for _, line := range lines {
+		marker := " "
+		if line.differs {
+			marker = "*"
+		}
+		text := strings.TrimRight(fmt.Sprintf("%s %-16s %-32s %-32s", marker, truncate(line.field, 16), truncate(line.a, 32), truncate(line.b, 32)), " ")
+		if line.differs {
+			text = diffHighlightStyle.Render(text)
+		}
+		builder.WriteString(text + "\n")
+	}
+
+	return builder.String()
+}
+
+func truncate(str string, length int) string {
+	runes := []rune(str)
+	if len(runes) <= length {
+		return str
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+type CompareEnvironmentMsg struct {
+	environment  Environment
+	environments []Environment
+}
+
+func compareEnvironmentMsg(environmentId string, serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		var msg CompareEnvironmentMsg
+		msg.environment, msg.environments = getEnvironmentWithSiblings(environmentId, serviceId)
+		return msg
+	}
+}
+
+// getEnvironmentWithSiblings returns the environment and the other environments of the service
+func getEnvironmentWithSiblings(environmentId string, serviceId string) (Environment, []Environment) {
+	var environment Environment
+	siblings := []Environment{}
+
+	environments, ok := getEnvironments(serviceId).(EnvironmentsMsg)
+	if !ok {
+		return environment, siblings
+	}
+
+	for _, e := range environments {
+		if e.Id == environmentId {
+			environment = e
+		} else {
+			siblings = append(siblings, e)
+		}
+	}
+
+	return environment, siblings
+}
+
+var compareEnvironmentId string
+
+func createCompareForm(m *model, source Environment, targets []Environment) {
+
+	targetOptions := []huh.Option[string]{}
+	for _, target := range targets {
+		targetOptions = append(targetOptions, huh.NewOption(target.Name+" ("+target.Branch+")", target.Id))
+	}
+
+	compareEnvironmentId = ""
+
+	m.compareForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Compare " + source.Name + " with").
+				Options(targetOptions...).
+				Value(&compareEnvironmentId),
+		),
+	)
+
+	m.compareForm.Init()
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -39,64 +43,322 @@ func (d envMenuItemDelegate) Render(w io.Writer, m list.Model, index int, listIt
 	fmt.Fprint(w, fn(i.title))
 }
 
+// environmentRows returns rows of the Environments table, the first row adds a new environment
+func environmentRows(m model) []table.Row {
+	rows := []table.Row{}
+
+	var tableRow []string
+	tableRow = append(tableRow, ADD_ENVIRONMENT_STRING)
+	tableRow = append(tableRow, "")
+	tableRow = append(tableRow, "")
+	tableRow = append(tableRow, "")
+	tableRow = append(tableRow, "")
+	tableRow = append(tableRow, "")
+	tableRow = append(tableRow, "")
+
+	rows = append(rows, tableRow)
+
+	//Preview environments are listed after long-lived environments
+	previewRows := []table.Row{}
+
+	for _, environment := range filterEnvironments(m) {
+
+		var tableRow []string
+		tableRow = append(tableRow, environment.Id)
+		tableRow = append(tableRow, environment.Name)
+		if isImageService(m.selectedService) {
+			tableRow = append(tableRow, m.selectedService.Image.Tag)
+		} else {
+			tableRow = append(tableRow, environment.Branch)
+		}
+		tableRow = append(tableRow, deploymentStatusCell(m, environment))
+		tableRow = append(tableRow, healthCell(environment))
+		tableRow = append(tableRow, protectedMark(environment))
+		tableRow = append(tableRow, selectionMark(m.selectedEnvironmentIds, environment.Id))
+
+		if environment.IsPreview {
+			previewRows = append(previewRows, tableRow)
+		} else {
+			rows = append(rows, tableRow)
+		}
+	}
+
+	if len(previewRows) > 0 {
+		rows = append(rows, table.Row{"", "", "", "", "", "", ""}, table.Row{PREVIEW_ENVIRONMENTS_STRING, "", "", "", "", "", ""})
+		rows = append(rows, previewRows...)
+	}
+
+	return rows
+}
+
+// environmentRowIds returns IDs of environments listed in the Environments table
+func environmentRowIds(m model) []string {
+	ids := []string{}
+	for _, row := range m.environmentList.Rows() {
+		if isEnvironmentRow(row) {
+			ids = append(ids, row[0])
+		}
+	}
+	return ids
+}
+
+// isEnvironmentRow reports whether the row is an environment and not a service row
+func isEnvironmentRow(row table.Row) bool {
+	return row[0] != "" && row[0] != ADD_ENVIRONMENT_STRING && row[0] != PREVIEW_ENVIRONMENTS_STRING
+}
+
+// parseDomains parses domains typed separated by commas, empty ones are skipped
+func parseDomains(value string) []string {
+	domains := []string{}
+	for _, domain := range strings.Split(value, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
 func createEnvironmentDetails(m *model, machineOptions []huh.Option[string], confirmationTitle string, confirmationBtn string) {
 
-	m.newEnvironmentForm = huh.NewForm(
+	//A cloned environment can't reuse the domains of the original one
+	clonedEnvironment := m.clonedEnvironment
+
+	detailsFields := []huh.Field{
+		huh.NewInput().
+			Title("Environment Name").
+			Value(&newEnvironmentName).
+			Validate(func(str string) error {
+				/*if str == "Frank" {
+				}*/
+				return nil
+			}),
+	}
+
+	//Services deployed from a registry image run the tag of the service, they have no branches
+	if !isImageService(m.selectedService) {
+		detailsFields = append(detailsFields, huh.NewInput().
+			Title("Branch").
+			Placeholder("main, master, dev, etc").
+			Value(&newEnvironmentBranchName).
+			Validate(func(str string) error {
+				/*if str == "Frank" {
+				}*/
+				return nil
+			}))
+	}
+
+	detailsGroup := huh.NewGroup(append(detailsFields,
+		huh.NewInput().
+			Title("Port").
+			Placeholder("4008, 5005, etc").
+			Value(&newEnvironmentPort).
+			Validate(func(str string) error {
+				/*if str == "Frank" {
+				}*/
+				return nil
+			}),
+		huh.NewInput().
+			Title("Domains").
+			Description("Without HTTPS—for example, project.com—the DNS A record for each domain or subdomain should resolve to the IP address of the load balancer machine, separate several domains with commas").
+			Placeholder("project.com, www.project.com").
+			Value(&newEnvironmentDomains).
+			Validate(func(str string) error {
+				for _, domain := range parseDomains(str) {
+					if slices.Contains(clonedEnvironment.Domains, domain) {
+						return errors.New(domain + " is used by " + clonedEnvironment.Name + ", rename it")
+					}
+				}
+				return nil
+			}),
+		huh.NewMultiSelect[string]().
+			Title("Choose Servers to Deploy").
+			Value(&newEnvironmentMachines).
+			Options(machineOptions...),
+		huh.NewConfirm().
+			Title("Protected").
+			Description("Deploy, edit and delete require a confirmation phrase or an approval by another user").
+			Affirmative("Yes").
+			Negative("No").
+			Value(&newEnvironmentProtected),
+	)...)
+
+	confirmationGroup := huh.NewGroup(append(healthCheckFields(),
+		huh.NewConfirm().
+			Key("done").
+			Title(confirmationTitle).
+			Validate(func(v bool) error {
+				if !v {
+					screenType = 1
+				}
+				return nil
+			}).
+			Affirmative(confirmationBtn).
+			Negative("Cancel").
+			Value(&newEnvironmentIsAdd),
+	)...)
+
+	//Resources and deployment strategy pages are shown between details and health check
+	editedEnvironmentId := ""
+	if screenType == SCREEN_TYPE_EDIT_ENVIRONMENT {
+		editedEnvironmentId = m.selectedEnvironment.Id
+	}
+	groups := append([]*huh.Group{detailsGroup, resourcesGroup(machineOptions, editedEnvironmentId)}, deploymentStrategyGroups()...)
+	m.newEnvironmentForm = huh.NewForm(append(groups, confirmationGroup)...).WithHeight(m.screenHeight - 14)
+
+	m.newEnvironmentForm.Init()
+}
+
+var (
+	promoteTargetEnvironmentId string
+	promoteIsConfirmed         bool
+)
+
+// createPromoteForm asks for the environment that should run the commit deployed in the source environment
+func createPromoteForm(m *model, source Environment, targets []Environment) {
+
+	targetOptions := []huh.Option[string]{}
+	for _, target := range targets {
+		targetOptions = append(targetOptions, huh.NewOption(target.Name+" ("+target.Branch+")", target.Id))
+	}
+
+	promoteTargetEnvironmentId = ""
+	promoteIsConfirmed = true
+
+	m.promoteForm = huh.NewForm(
 		huh.NewGroup(
-			huh.NewInput().
-				Title("Environment Name").
-				Value(&newEnvironmentName).
-				Validate(func(str string) error {
-					/*if str == "Frank" {
-					}*/
-					return nil
-				}),
-			huh.NewInput().
-				Title("Branch").
-				Placeholder("main, master, dev, etc").
-				Value(&newEnvironmentBranchName).
-				Validate(func(str string) error {
-					/*if str == "Frank" {
-					}*/
-					return nil
-				}),
-			huh.NewInput().
-				Title("Port").
-				Placeholder("4008, 5005, etc").
-				Value(&newEnvironmentPort).
-				Validate(func(str string) error {
-					/*if str == "Frank" {
-					}*/
-					return nil
-				}),
-			huh.NewInput().
-				Title("Domain").
-				Description("Without HTTPS—for example, project.com—the DNS A record for that domain or subdomain should resolve to the IP address of the load balancer machine").
-				Placeholder("project.com").
-				Value(&newEnvironmentDomain).
-				Validate(func(str string) error {
-					/*if str == "Frank" {
-					}*/
-					return nil
-				}),
-			huh.NewMultiSelect[string]().
-				Title("Choose Servers to Deploy").
-				Value(&newEnvironmentMachines).
-				Options(machineOptions...),
+			huh.NewSelect[string]().
+				Title("Promote to Environment").
+				Description("Commit "+shortCommit(source.LastDeploymentCommit)+" running in "+source.Name+" will be deployed to the selected environment").
+				Options(targetOptions...).
+				Value(&promoteTargetEnvironmentId),
 			huh.NewConfirm().
 				Key("done").
-				Title(confirmationTitle).
-				Validate(func(v bool) error {
-					if !v {
-						screenType = 1
-					}
-					return nil
-				}).
-				Affirmative(confirmationBtn).
+				Title("Deploy this commit?").
+				Affirmative("Deploy").
 				Negative("Cancel").
-				Value(&newEnvironmentIsAdd),
+				Value(&promoteIsConfirmed),
 		),
-	).WithHeight(m.screenHeight - 14)
+	)
 
-	m.newEnvironmentForm.Init()
+	m.promoteForm.Init()
+}
+
+// promoteCommit deploys the commit running in the promote source to the target environment,
+// protected targets are confirmed with the deploy phrase or an approval first
+func promoteCommit(m *model, target Environment) tea.Cmd {
+	previousDeploymentId, err := getPreviousDeploymentId(target.Id)
+	var queuedAt time.Time
+	if err == nil {
+		queuedAt, err = deployOutsideFreeze(target, m.promoteSource.LastDeploymentCommit)
+	}
+	if err != nil {
+		screenType = SCREEN_TYPE_PROMOTE_ENVIRONMENT
+		m.promoteForm = nil
+		m.promoteHint = "\n " + deploymentErrorHint(err) + "\n Press ESC to return to the Environment menu"
+		return nil
+	}
+
+	m.selectedEnvironment.Id = target.Id
+	m.selectedEnvironment.Name = target.Name
+	screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+	if !queuedAt.IsZero() {
+		m.deploymentHint = queuedDeploymentHint(target, queuedAt)
+		return nil
+	}
+	m.deploymentHint = ""
+	return tea.Batch(trackDeployment(m, target), showDeploymentProgress(m, target.Id, previousDeploymentId))
+}
+
+func shortCommit(commitHash string) string {
+	if len(commitHash) > 7 {
+		return commitHash[:7]
+	}
+	return commitHash
+}
+
+// runEnvironmentMenuAction runs the action of the Environment menu, actions on protected
+// environments run after the confirmation phrase is typed or the request is approved
+func runEnvironmentMenuAction(m *model, action string) tea.Cmd {
+	if action == MENU_BACK {
+		//Go back
+		screenType = SCREEN_TYPE_ENVIRONMENTS
+		return nil
+	} else if action == MENU_DEPLOY {
+		//Deploy
+		screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+		environment := environmentForDeployment(*m, m.selectedEnvironment.Id)
+		previousDeploymentId, err := getPreviousDeploymentId(environment.Id)
+		var queuedAt time.Time
+		if err == nil {
+			queuedAt, err = deployOutsideFreeze(environment, "")
+		}
+		if err != nil {
+			m.deploymentHint = deploymentErrorHint(err)
+			return nil
+		}
+		if !queuedAt.IsZero() {
+			m.deploymentHint = queuedDeploymentHint(environment, queuedAt)
+			return nil
+		}
+		m.deploymentHint = ""
+		return tea.Batch(trackDeployment(m, environment), showDeploymentProgress(m, environment.Id, previousDeploymentId))
+	} else if action == MENU_DEPLOYMENT_PROGRESS {
+		//Per-machine progress with pause, resume and abort
+		screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+		m.deploymentHint = ""
+		return showDeploymentProgress(m, m.selectedEnvironment.Id, "")
+	} else if action == MENU_SCHEDULE_DEPLOY {
+		//Deploy at a specific time
+		screenType = SCREEN_TYPE_SCHEDULE_DEPLOY
+		createScheduleDeployForm(m, environmentForDeployment(*m, m.selectedEnvironment.Id))
+		return nil
+	} else if action == MENU_CANCEL_SCHEDULED_DEPLOY {
+		screenType = SCREEN_TYPE_CANCEL_SCHEDULED_DEPLOY
+		createCancelScheduledDeployForm(m)
+		return nil
+	} else if action == MENU_FREEZE_WINDOWS {
+		//Periods without deployments
+		screenType = SCREEN_TYPE_FREEZE_WINDOWS
+		createFreezeWindowsForm(m, environmentForDeployment(*m, m.selectedEnvironment.Id))
+		return nil
+	} else if strings.HasPrefix(action, MENU_VOLUME_BACKUPS_PREFIX) {
+		//Snapshots of a volume
+		environment := environmentForDeployment(*m, m.selectedEnvironment.Id)
+		volumeName := strings.TrimPrefix(action, MENU_VOLUME_BACKUPS_PREFIX)
+		for _, volume := range environment.Volumes {
+			if volume.Name == volumeName {
+				return showBackups(m, volumeBackupResource(environment, volume))
+			}
+		}
+		return nil
+	} else if action == MENU_VOLUMES {
+		//Data kept across deployments
+		screenType = SCREEN_TYPE_VOLUMES
+		createVolumesForm(m, environmentForDeployment(*m, m.selectedEnvironment.Id))
+		return nil
+	} else if action == MENU_EDIT {
+		//Edit environment
+		return editEnvironmentMsg(m.selectedEnvironment.Id, m.selectedService.Id)
+	} else if action == MENU_CLONE {
+		//Clone environment
+		return cloneEnvironmentMsg(m.selectedEnvironment.Id, m.selectedService.Id)
+	} else if action == MENU_PROMOTE {
+		//Promote the deployed commit to another environment
+		return promoteEnvironmentMsg(m.selectedEnvironment.Id, m.selectedService.Id)
+	} else if action == MENU_COMPARE {
+		//Compare with another environment
+		return compareEnvironmentMsg(m.selectedEnvironment.Id, m.selectedService.Id)
+	} else if action == MENU_APPROVALS {
+		//Approve a request of another user
+		screenType = SCREEN_TYPE_APPROVALS
+		createApprovalForm(m)
+		return nil
+	} else if action == MENU_DELETE {
+		//Delete environment
+		m.deleteEnvConfirmation.SetValue("")
+		m.deleteEnvConfirmation.Focus()
+		screenType = SCREEN_TYPE_ENV_DELETE_CONFIRMATION
+		return nil
+	}
+	return nil
 }
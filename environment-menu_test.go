@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseDomains(t *testing.T) {
+	got := parseDomains(" a.com, ,www.a.com,")
+	want := []string{"a.com", "www.a.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseDomains() = %q, want %q", got, want)
+	}
+}
+
+// A commit is promoted to a protected environment after its deploy phrase is typed
+func TestPromoteToProtectedEnvironment(t *testing.T) {
+	deployed := []string{}
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/deploy/") {
+			deployed = append(deployed, r.URL.Path)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	previousScreenType := screenType
+	t.Cleanup(func() { screenType = previousScreenType })
+
+	m := newModel()
+	m.promoteSource = Environment{Id: "e1", Name: "staging", LastDeploymentCommit: "abc1234"}
+	target := Environment{Id: "e2", Name: "prod", Protected: true}
+
+	m.selectedEnvironment = target
+	startProtectedAction(&m, PROTECTED_ACTION_PROMOTE)
+	if phrase := protectedActionPhrase(m); phrase != "deploy prod" {
+		t.Errorf("phrase = %q, want deploy prod", phrase)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.selectedEnvironment.Id != "e1" || screenType != SCREEN_TYPE_ENV_MENU || len(deployed) != 0 {
+		t.Fatalf("ESC left %s selected on screen %d, deployed %q", m.selectedEnvironment.Name, screenType, deployed)
+	}
+
+	m.selectedEnvironment = target
+	startProtectedAction(&m, PROTECTED_ACTION_PROMOTE)
+	m.protectedConfirmation.SetValue("deploy prod")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !slices.Equal(deployed, []string{"/deploy/environment/e2/commit/abc1234"}) {
+		t.Errorf("deploy requests = %q, want the staging commit on prod", deployed)
+	}
+}
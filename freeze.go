@@ -0,0 +1,350 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const MENU_SCHEDULE_DEPLOY = "Schedule Deploy"
+const MENU_FREEZE_WINDOWS = "Freeze Windows"
+const MENU_CANCEL_SCHEDULED_DEPLOY = "Cancel Scheduled Deploy"
+
+// SCHEDULE_TIME_LAYOUT is used to type times of scheduled deployments and dates of freeze windows
+const SCHEDULE_TIME_LAYOUT = "2006-01-02 15:04"
+
+const MINUTES_PER_WEEK = 7 * 24 * 60
+
+var weekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var (
+	scheduleDeployAt            string
+	scheduleDeployIsConfirmed   bool
+	freezeWindows               string
+	freezeMode                  string
+	freezeIsSave                bool
+	cancelScheduledDeploymentId string
+	cancelScheduledIsConfirmed  bool
+
+	scheduledDeploymentsStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#bfbfbf"))
+)
+
+// weeklyMinute parses "Fri 12:00" to minutes since Sunday 00:00
+func weeklyMinute(value string) (int, bool) {
+	day, clock, found := strings.Cut(strings.TrimSpace(value), " ")
+	if !found {
+		return 0, false
+	}
+
+	weekday := slices.IndexFunc(weekdays, func(weekday string) bool { return strings.EqualFold(weekday, day) })
+	if weekday < 0 {
+		return 0, false
+	}
+
+	parsed, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, false
+	}
+	return weekday*24*60 + parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// parseFreezeWindows parses comma separated windows like "Fri 12:00 - Mon 08:00, 2026-12-24 00:00 - 2026-12-27 00:00"
+func parseFreezeWindows(value string) ([]FreezeWindow, error) {
+	windows := []FreezeWindow{}
+
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		start, end, found := strings.Cut(part, " - ")
+		if !found {
+			return nil, errors.New("separate the start and the end of " + strings.TrimSpace(part) + " with ' - '")
+		}
+
+		window := FreezeWindow{Start: strings.TrimSpace(start), End: strings.TrimSpace(end)}
+		_, weeklyStart := weeklyMinute(window.Start)
+		_, weeklyEnd := weeklyMinute(window.End)
+		if weeklyStart && weeklyEnd {
+			windows = append(windows, window)
+			continue
+		}
+
+		startTime, startErr := time.Parse(SCHEDULE_TIME_LAYOUT, window.Start)
+		endTime, endErr := time.Parse(SCHEDULE_TIME_LAYOUT, window.End)
+		if startErr != nil || endErr != nil {
+			return nil, errors.New(strings.TrimSpace(part) + " should look like 'Fri 12:00 - Mon 08:00' or '2026-12-24 00:00 - 2026-12-27 00:00'")
+		}
+		if !endTime.After(startTime) {
+			return nil, errors.New(strings.TrimSpace(part) + " ends before it starts")
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+func freezeWindowsString(windows []FreezeWindow) string {
+	parts := []string{}
+	for _, window := range windows {
+		parts = append(parts, window.Start+" - "+window.End)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// freezeWindowEnd returns when the window ends if the moment is inside the window
+func freezeWindowEnd(window FreezeWindow, moment time.Time) (time.Time, bool) {
+	moment = moment.UTC()
+
+	start, weeklyStart := weeklyMinute(window.Start)
+	end, weeklyEnd := weeklyMinute(window.End)
+	if weeklyStart && weeklyEnd {
+		now := int(moment.Weekday())*24*60 + moment.Hour()*60 + moment.Minute()
+		//Windows like Fri 18:00 - Mon 08:00 continue in the next week
+		sinceStart := (now - start + MINUTES_PER_WEEK) % MINUTES_PER_WEEK
+		length := (end - start + MINUTES_PER_WEEK) % MINUTES_PER_WEEK
+		if sinceStart >= length {
+			return time.Time{}, false
+		}
+		return moment.Truncate(time.Minute).Add(time.Duration(length-sinceStart) * time.Minute), true
+	}
+
+	startTime, startErr := time.Parse(SCHEDULE_TIME_LAYOUT, window.Start)
+	endTime, endErr := time.Parse(SCHEDULE_TIME_LAYOUT, window.End)
+	if startErr != nil || endErr != nil || moment.Before(startTime) || !moment.Before(endTime) {
+		return time.Time{}, false
+	}
+	return endTime, true
+}
+
+// freezeEnd returns when deployments of the environment are allowed again, windows that
+// follow each other without a gap are treated as one
+func freezeEnd(environment Environment, moment time.Time) (time.Time, bool) {
+	frozenUntil := moment
+	frozen := false
+
+	for range environment.FreezeWindows {
+		extended := false
+		for _, window := range environment.FreezeWindows {
+			if end, ok := freezeWindowEnd(window, frozenUntil); ok && end.After(frozenUntil) {
+				frozenUntil = end
+				frozen = true
+				extended = true
+			}
+		}
+		if !extended {
+			break
+		}
+	}
+
+	return frozenUntil, frozen
+}
+
+func formatScheduleTime(moment time.Time) string {
+	return moment.Local().Format("Mon 2 Jan 15:04")
+}
+
+func formatUntil(moment time.Time) string {
+	until := time.Until(moment).Round(time.Minute)
+	if until <= 0 {
+		return "starting"
+	}
+	if until < time.Hour {
+		return fmt.Sprintf("in %dm", int(until.Minutes()))
+	}
+	return fmt.Sprintf("in %dh%02dm", int(until.Hours()), int(until.Minutes())%60)
+}
+
+// deployOutsideFreeze deploys the environment right away, inside a freeze window the deployment
+// is queued until the window ends or rejected depending on the freeze mode of the environment.
+// The returned time is when a queued deployment starts, it's zero if the deployment has started.
+func deployOutsideFreeze(environment Environment, commitHash string) (time.Time, error) {
+	if end, frozen := freezeEnd(environment, time.Now()); frozen {
+		if environment.FreezeMode != FREEZE_MODE_QUEUE {
+			return time.Time{}, fmt.Errorf("deployments are rejected until %s, %s is in a freeze window", formatScheduleTime(end), environment.Name)
+		}
+		if !postScheduledDeployment(environment.Id, commitHash, end) {
+			return time.Time{}, errors.New("cannot queue the deployment, check that the lighthouse is reachable")
+		}
+		return end, nil
+	}
+
+	if commitHash == "" {
+		if !deployEnvironment(environment.Id) {
+			return time.Time{}, errors.New("cannot schedule the deployment, check that the lighthouse is reachable")
+		}
+	} else if !deployEnvironmentCommit(environment.Id, commitHash) {
+		return time.Time{}, errors.New("cannot schedule the deployment of " + shortCommit(commitHash) + ", check that the lighthouse is reachable")
+	}
+	return time.Time{}, nil
+}
+
+// deploymentErrorHint turns an error of deployOutsideFreeze into a sentence
+func deploymentErrorHint(err error) string {
+	hint := err.Error()
+	return strings.ToUpper(hint[:1]) + hint[1:] + "."
+}
+
+// queuedDeploymentHint tells that the deployment will start when the freeze window ends
+func queuedDeploymentHint(environment Environment, queuedAt time.Time) string {
+	return environment.Name + " is in a freeze window, the deployment is queued for " + formatScheduleTime(queuedAt) + "."
+}
+
+// parseScheduleTime parses a time typed in the local time zone
+func parseScheduleTime(environment Environment, value string) (time.Time, error) {
+	scheduledAt, err := time.ParseInLocation(SCHEDULE_TIME_LAYOUT, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return scheduledAt, errors.New("enter the time like " + time.Now().Add(time.Hour).Format(SCHEDULE_TIME_LAYOUT))
+	}
+	if !scheduledAt.After(time.Now()) {
+		return scheduledAt, errors.New("this time has already passed")
+	}
+	if end, frozen := freezeEnd(environment, scheduledAt); frozen {
+		return scheduledAt, errors.New("this time is inside a freeze window that ends " + formatScheduleTime(end))
+	}
+	return scheduledAt, nil
+}
+
+func environmentScheduledDeployments(m model, environmentId string) []ScheduledDeployment {
+	scheduledDeployments := []ScheduledDeployment{}
+	for _, scheduledDeployment := range m.scheduledDeployments {
+		if scheduledDeployment.EnvironmentId == environmentId {
+			scheduledDeployments = append(scheduledDeployments, scheduledDeployment)
+		}
+	}
+	return scheduledDeployments
+}
+
+// createScheduleDeployForm asks when the environment should be deployed
+func createScheduleDeployForm(m *model, environment Environment) {
+
+	scheduleDeployAt = time.Now().Add(time.Hour).Truncate(time.Hour).Format(SCHEDULE_TIME_LAYOUT)
+	scheduleDeployIsConfirmed = true
+
+	m.scheduleDeployForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Deploy at").
+				Description("Local time, the lighthouse deploys the latest commit of "+environment.Branch+" at this time").
+				Placeholder(SCHEDULE_TIME_LAYOUT).
+				Value(&scheduleDeployAt).
+				Validate(func(str string) error {
+					_, err := parseScheduleTime(environment, str)
+					return err
+				}),
+			huh.NewConfirm().
+				Key("done").
+				Title("Schedule this deployment?").
+				Affirmative("Schedule").
+				Negative("Cancel").
+				Value(&scheduleDeployIsConfirmed),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.scheduleDeployForm.Init()
+}
+
+// createFreezeWindowsForm edits freeze windows of the environment
+func createFreezeWindowsForm(m *model, environment Environment) {
+
+	freezeWindows = freezeWindowsString(environment.FreezeWindows)
+	freezeMode = environment.FreezeMode
+	if freezeMode == "" {
+		freezeMode = FREEZE_MODE_REJECT
+	}
+	freezeIsSave = true
+
+	m.freezeWindowsForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Freeze Windows").
+				Description("UTC, separated by commas, for example: Fri 12:00 - Mon 08:00, 2026-12-24 00:00 - 2026-12-27 00:00").
+				Placeholder("Fri 12:00 - Mon 08:00").
+				Value(&freezeWindows).
+				Validate(func(str string) error {
+					_, err := parseFreezeWindows(str)
+					return err
+				}),
+			huh.NewSelect[string]().
+				Title("Deployments inside a freeze window").
+				Description("Applies to webhook and manual deployments").
+				Options(
+					huh.NewOption("Reject", FREEZE_MODE_REJECT),
+					huh.NewOption("Queue until the window ends", FREEZE_MODE_QUEUE),
+				).
+				Value(&freezeMode),
+			huh.NewConfirm().
+				Key("done").
+				Title("Save freeze windows?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&freezeIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.freezeWindowsForm.Init()
+}
+
+// createCancelScheduledDeployForm asks which scheduled deployment of the environment should be canceled
+func createCancelScheduledDeployForm(m *model) {
+
+	scheduledOptions := []huh.Option[string]{}
+	for _, scheduledDeployment := range environmentScheduledDeployments(*m, m.selectedEnvironment.Id) {
+		title := formatScheduleTime(scheduledDeployment.ScheduledAt)
+		if scheduledDeployment.Commit != "" {
+			title += " (commit " + shortCommit(scheduledDeployment.Commit) + ")"
+		}
+		scheduledOptions = append(scheduledOptions, huh.NewOption(title, scheduledDeployment.Id))
+	}
+
+	cancelScheduledDeploymentId = ""
+	cancelScheduledIsConfirmed = true
+
+	m.cancelScheduledDeployForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Scheduled Deployments").
+				Options(scheduledOptions...).
+				Value(&cancelScheduledDeploymentId),
+			huh.NewConfirm().
+				Key("done").
+				Title("Cancel this deployment?").
+				Affirmative("Cancel Deployment").
+				Negative("Keep").
+				Value(&cancelScheduledIsConfirmed),
+		),
+	)
+
+	m.cancelScheduledDeployForm.Init()
+}
+
+// scheduledDeploymentsView lists upcoming deployments and freeze windows in effect below the Environments table
+func scheduledDeploymentsView(m model) string {
+	lines := []string{}
+
+	for _, environment := range m.environments {
+		if end, frozen := freezeEnd(environment, time.Now()); frozen {
+			lines = append(lines, "❄ "+environment.Name+" is frozen until "+formatScheduleTime(end))
+		}
+	}
+
+	scheduledDeployments := slices.Clone(m.scheduledDeployments)
+	slices.SortFunc(scheduledDeployments, func(a, b ScheduledDeployment) int { return a.ScheduledAt.Compare(b.ScheduledAt) })
+	for _, scheduledDeployment := range scheduledDeployments {
+		environment := environmentForDeployment(m, scheduledDeployment.EnvironmentId)
+		if environment.Id == "" {
+			continue
+		}
+		lines = append(lines, "⏲ "+environment.Name+" deploys "+formatScheduleTime(scheduledDeployment.ScheduledAt)+" ("+formatUntil(scheduledDeployment.ScheduledAt)+")")
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return listHelpStyle.Render(scheduledDeploymentsStyle.Render(strings.Join(lines, "\n"))) + "\n"
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFreezeWindows(t *testing.T) {
+	windows, err := parseFreezeWindows("Fri 12:00 - Mon 08:00, 2026-12-24 00:00 - 2026-12-27 00:00,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[0] != (FreezeWindow{"Fri 12:00", "Mon 08:00"}) || windows[1] != (FreezeWindow{"2026-12-24 00:00", "2026-12-27 00:00"}) {
+		t.Errorf("parseFreezeWindows() = %+v", windows)
+	}
+
+	for _, value := range []string{
+		"Fri 12:00 Mon 08:00",
+		"Friday 12:00 - Mon 08:00",
+		"Fri 25:00 - Mon 08:00",
+		"2026-12-27 00:00 - 2026-12-24 00:00",
+		"Fri 12:00 - 2026-12-27 00:00",
+	} {
+		if _, err := parseFreezeWindows(value); err == nil {
+			t.Errorf("parseFreezeWindows(%q) returned no error", value)
+		}
+	}
+}
+
+func TestFreezeEnd(t *testing.T) {
+	//2026-10-18 is a Sunday
+	sunday := time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)
+	weekend := FreezeWindow{"Fri 18:00", "Mon 08:00"}
+	tests := []struct {
+		name    string
+		windows []FreezeWindow
+		moment  time.Time
+		end     time.Time
+		frozen  bool
+	}{
+		{"continues into the next week", []FreezeWindow{weekend}, sunday, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), true},
+		{"starts on friday", []FreezeWindow{weekend}, time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), true},
+		{"ends on monday", []FreezeWindow{weekend}, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), time.Time{}, false},
+		{"outside", []FreezeWindow{weekend}, time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC), time.Time{}, false},
+		{"adjacent windows", []FreezeWindow{weekend, {"Mon 08:00", "Mon 12:00"}}, sunday, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), true},
+		{"dates", []FreezeWindow{{"2026-12-24 00:00", "2026-12-27 00:00"}}, time.Date(2026, 12, 25, 9, 0, 0, 0, time.UTC), time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, test := range tests {
+		end, frozen := freezeEnd(Environment{FreezeWindows: test.windows}, test.moment)
+		if frozen != test.frozen || (frozen && !end.Equal(test.end)) {
+			t.Errorf("%s: freezeEnd() = %v, %v, want %v, %v", test.name, end, frozen, test.end, test.frozen)
+		}
+	}
+}
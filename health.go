@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const HEALTH_CHECK_DEFAULT_STATUS = 200
+const HEALTH_CHECK_DEFAULT_TIMEOUT = 5
+const HEALTH_CHECK_DEFAULT_RETRIES = 3
+
+var (
+	newEnvironmentHealthPath    string
+	newEnvironmentHealthStatus  string
+	newEnvironmentHealthTimeout string
+	newEnvironmentHealthRetries string
+	newEnvironmentAutoRollback  bool
+)
+
+// setHealthCheckForm fills health check fields of the environment form
+func setHealthCheckForm(environment Environment) {
+	healthCheck := environment.HealthCheck
+	if healthCheck.ExpectedStatus == 0 {
+		healthCheck.ExpectedStatus = HEALTH_CHECK_DEFAULT_STATUS
+	}
+	if healthCheck.TimeoutSeconds == 0 {
+		healthCheck.TimeoutSeconds = HEALTH_CHECK_DEFAULT_TIMEOUT
+	}
+	if healthCheck.Path == "" && healthCheck.Retries == 0 {
+		healthCheck.Retries = HEALTH_CHECK_DEFAULT_RETRIES
+	}
+
+	newEnvironmentHealthPath = healthCheck.Path
+	newEnvironmentHealthStatus = strconv.Itoa(healthCheck.ExpectedStatus)
+	newEnvironmentHealthTimeout = strconv.Itoa(healthCheck.TimeoutSeconds)
+	newEnvironmentHealthRetries = strconv.Itoa(healthCheck.Retries)
+	newEnvironmentAutoRollback = environment.AutoRollback
+}
+
+// healthCheckFromForm returns the health check typed in the environment form, fields are validated by the form
+func healthCheckFromForm() HealthCheck {
+	if strings.TrimSpace(newEnvironmentHealthPath) == "" {
+		return HealthCheck{}
+	}
+
+	expectedStatus, _ := strconv.Atoi(newEnvironmentHealthStatus)
+	timeoutSeconds, _ := strconv.Atoi(newEnvironmentHealthTimeout)
+	retries, _ := strconv.Atoi(newEnvironmentHealthRetries)
+	return HealthCheck{
+		Path:           strings.TrimSpace(newEnvironmentHealthPath),
+		ExpectedStatus: expectedStatus,
+		TimeoutSeconds: timeoutSeconds,
+		Retries:        retries,
+	}
+}
+
+func validateNumber(min int, max int) func(string) error {
+	return func(str string) error {
+		number, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil || number < min || number > max {
+			return fmt.Errorf("enter a number from %d to %d", min, max)
+		}
+		return nil
+	}
+}
+
+// healthCheckFields are shown on the second page of the environment form
+func healthCheckFields() []huh.Field {
+	return []huh.Field{
+		huh.NewInput().
+			Title("Health Check Path").
+			Description("The lighthouse sends GET requests to this path on the port of the environment after each deployment, leave it empty to disable health checks").
+			Placeholder("/health").
+			Value(&newEnvironmentHealthPath).
+			Validate(func(str string) error {
+				if str != "" && !strings.HasPrefix(str, "/") {
+					return errors.New("the path should start with /")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Expected Status").
+			Value(&newEnvironmentHealthStatus).
+			Validate(validateNumber(100, 599)),
+		huh.NewInput().
+			Title("Timeout, seconds").
+			Value(&newEnvironmentHealthTimeout).
+			Validate(validateNumber(1, 300)),
+		huh.NewInput().
+			Title("Retries").
+			Description("Failed requests are repeated before the environment is reported unhealthy").
+			Value(&newEnvironmentHealthRetries).
+			Validate(validateNumber(0, 100)),
+		huh.NewConfirm().
+			Title("Roll back automatically").
+			Description("Redeploy the previous commit if a new deployment fails its health check").
+			Affirmative("Yes").
+			Negative("No").
+			Value(&newEnvironmentAutoRollback),
+	}
+}
+
+// deploymentStatusLabel returns the status without underscores
+func deploymentStatusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", " ")
+}
+
+// healthCell renders the Health column of the Environments table
+func healthCell(environment Environment) string {
+	switch {
+	case environment.HealthCheck.Path == "":
+		return ""
+	case environment.Health == HEALTH_STATUS_HEALTHY:
+		return deploymentDeployedStyle.Render("● " + environment.Health)
+	case environment.Health == HEALTH_STATUS_UNHEALTHY:
+		return deploymentFailedStyle.Render("● " + environment.Health)
+	}
+	return "○ unknown"
+}
+
+// healthCheckDescription describes the health check, for example "GET /health expects 200, timeout 5s, 3 retries"
+func healthCheckDescription(healthCheck HealthCheck) string {
+	if healthCheck.Path == "" {
+		return "no health check"
+	}
+	retries := fmt.Sprintf("%d retries", healthCheck.Retries)
+	if healthCheck.Retries == 1 {
+		retries = "1 retry"
+	}
+	return fmt.Sprintf("GET %s expects %d, timeout %ds, %s", healthCheck.Path, healthCheck.ExpectedStatus, healthCheck.TimeoutSeconds, retries)
+}
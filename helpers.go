@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -37,26 +38,32 @@ func openbrowser(url string) {
 }
 
 func executeScriptString(scriptString string) error {
+	return executeScriptStringTo(scriptString, os.Stdout)
+}
+
+// executeScriptStringTo runs the script and prints its stdout and stderr to output,
+// commands like export print to stderr to keep their stdout parseable
+func executeScriptStringTo(scriptString string, output io.Writer) error {
 
 	scriptContents := []byte(scriptString)
 
 	currentUser, err := user.Current()
 	if err != nil {
-		fmt.Println("Cannot get home directory:", err)
+		fmt.Fprintln(output, "Cannot get home directory:", err)
 	}
 
 	homeDir := currentUser.HomeDir
 
 	id, err := NanoId(7)
 	if err != nil {
-		fmt.Println("Cannot generate new NanoId for Deployment:", err)
+		fmt.Fprintln(output, "Cannot generate new NanoId for Deployment:", err)
 		return err
 	}
 	fileName := homeDir + "/" + id + ".sh"
 
 	err = os.WriteFile(fileName, scriptContents, 0644)
 	if err != nil {
-		fmt.Printf(" Cannot save script: %s\n", err.Error())
+		fmt.Fprintf(output, " Cannot save script: %s\n", err.Error())
 		return err
 	}
 
@@ -99,14 +106,14 @@ func executeScriptString(scriptString string) error {
 	}()
 
 	for t := range outch {
-		fmt.Println(t)
+		fmt.Fprintln(output, t)
 	}
 
 	wg.Wait()
 
 	err = os.Remove(fileName) //remove the script file
 	if err != nil {
-		fmt.Printf(" Cannot remove script: %s\n", err.Error())
+		fmt.Fprintf(output, " Cannot remove script: %s\n", err.Error())
 	}
 
 	return nil
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Output of the tunnel script must not end up in stdout of commands like export
+func TestExecuteScriptStringToKeepsStdoutClean(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	var output bytes.Buffer
+	err = executeScriptStringTo("echo out\necho err >&2\necho | xargs kill", &output)
+
+	os.Stdout = stdout
+	writer.Close()
+	written, _ := io.ReadAll(reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) > 0 {
+		t.Errorf("stdout = %q, want nothing", written)
+	}
+	if !strings.Contains(output.String(), "out") || !strings.Contains(output.String(), "err") {
+		t.Errorf("output = %q, want stdout and stderr of the script", output.String())
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Environments deployed to machines, shown before draining, deleting and bulk deleting machines
+
+// machineEnvironment is an environment deployed to a machine
+type machineEnvironment struct {
+	service     Service
+	environment Environment
+}
+
+// getMachineEnvironments returns environments deployed to any of the machines,
+// the list is incomplete on errors so callers must not treat it as empty
+func getMachineEnvironments(machineIds []string) ([]machineEnvironment, error) {
+	result := []machineEnvironment{}
+
+	services, ok := getServices().(ServicesMsg)
+	if !ok {
+		return nil, errors.New("services can't be loaded")
+	}
+	for _, service := range services {
+		environments, ok := getEnvironments(service.Id).(EnvironmentsMsg)
+		if !ok {
+			return nil, errors.New("environments of " + service.Name + " can't be loaded")
+		}
+		for _, environment := range environments {
+			if slices.ContainsFunc(environment.MachineIds, func(machineId string) bool { return slices.Contains(machineIds, machineId) }) {
+				result = append(result, machineEnvironment{service: service, environment: environment})
+			}
+		}
+	}
+	return result, nil
+}
+
+// environmentsOnMachine returns environments deployed to the machine
+func environmentsOnMachine(environments []machineEnvironment, machineId string) []machineEnvironment {
+	result := []machineEnvironment{}
+	for _, machineEnvironment := range environments {
+		if slices.Contains(machineEnvironment.environment.MachineIds, machineId) {
+			result = append(result, machineEnvironment)
+		}
+	}
+	return result
+}
+
+// hasOtherActiveMachine reports whether the environment keeps running when the machine is drained or deleted
+func hasOtherActiveMachine(environment Environment, machineId string, machines []Machine) bool {
+	for _, machine := range machines {
+		if machine.Id != machineId && !machine.Drained && slices.Contains(environment.MachineIds, machine.Id) {
+			return true
+		}
+	}
+	return false
+}
+
+// machineEnvironmentsView lists environments deployed to the machine with their domains,
+// environments without other active machines are highlighted
+func machineEnvironmentsView(machineId string, environments []machineEnvironment, machines []Machine) string {
+	if len(environments) == 0 {
+		return " No environments are deployed to this machine.\n"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(" Environments deployed to this machine:\n\n")
+	for _, machineEnvironment := range environments {
+		environment := machineEnvironment.environment
+		line := " • " + machineEnvironment.service.Name + " > " + environment.Name
+		if len(environment.Domains) > 0 {
+			line += " (" + strings.Join(environment.Domains, ", ") + ")"
+		}
+		if !hasOtherActiveMachine(environment, machineId, machines) {
+			line = bulkResultErrorStyle.Render(line + " - no other machines, will be unavailable")
+		}
+		builder.WriteString(line + "\n")
+	}
+	return builder.String()
+}
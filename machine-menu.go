@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+var (
+	editedMachineName   string
+	editedMachineTypes  []string
+	editedMachineIsSave bool
+)
+
+// machineRows returns rows of the Machines table, machines of other projects and filtered out machines are hidden
+func machineRows(m model) []table.Row {
+	rows := []table.Row{}
+
+	for _, machine := range filterMachines(m) {
+
+		var tableRow []string
+		tableRow = append(tableRow, machine.Id)
+		tableRow = append(tableRow, machine.Name)
+		tableRow = append(tableRow, machine.VPNIp)
+		tableRow = append(tableRow, machine.PublicIp)
+		tableRow = append(tableRow, machineStatus(m, machine))
+		tableRow = append(tableRow, machine.CPUUsage)
+		tableRow = append(tableRow, machine.MEMUsage)
+		tableRow = append(tableRow, machine.DiskUsage)
+		tableRow = append(tableRow, machine.VolumesUsage)
+		tableRow = append(tableRow, selectionMark(m.selectedMachineIds, machine.Id))
+
+		rows = append(rows, tableRow)
+	}
+
+	return rows
+}
+
+// machineRowIds returns IDs of machines listed in the Machines table
+func machineRowIds(m model) []string {
+	ids := []string{}
+	for _, row := range m.machineList.Rows() {
+		ids = append(ids, row[0])
+	}
+	return ids
+}
+
+func machineTypeOptions() []huh.Option[string] {
+	return []huh.Option[string]{
+		huh.NewOption("Server", MACHINE_TYPE_WORKLOAD),
+		huh.NewOption("Build Machine", MACHINE_TYPE_BUILDER),
+		huh.NewOption("Local Machine", MACHINE_TYPE_LOCAL_MACHINE),
+	}
+}
+
+// validateMachineName requires unique names, machines are shown by name in environment forms
+func validateMachineName(name string, machineId string, machines []Machine) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("machine name is required")
+	}
+	for _, machine := range machines {
+		if machine.Id != machineId && machine.Name == name {
+			return errors.New("machine with this name already exists")
+		}
+	}
+	return nil
+}
+
+func validateMachineTypes(types []string) error {
+	if len(types) == 0 {
+		return errors.New("choose at least one type")
+	}
+	return nil
+}
+
+// validateRemovedMachineTypes keeps at least one builder, add the type to another machine first
+func validateRemovedMachineTypes(machine Machine, types []string, machines []Machine) error {
+	for _, machineType := range soleMachineTypes(machine, machines) {
+		if !slices.Contains(types, machineType) {
+			return errors.New("this is the only machine of type '" + machineType + "', add the type to another machine first")
+		}
+	}
+	return nil
+}
+
+func createMachineDetails(m *model, machine Machine) {
+
+	editedMachineName = machine.Name
+	editedMachineTypes = slices.Clone(machine.Types)
+	editedMachineIsSave = true
+
+	machines := m.machines
+
+	m.editMachineForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Machine Name").
+				Value(&editedMachineName).
+				Validate(func(str string) error {
+					return validateMachineName(str, machine.Id, machines)
+				}),
+			huh.NewMultiSelect[string]().
+				Title("Choose Machine Types").
+				Options(machineTypeOptions()...).
+				Value(&editedMachineTypes).
+				Validate(func(types []string) error {
+					if err := validateMachineTypes(types); err != nil {
+						return err
+					}
+					return validateRemovedMachineTypes(machine, types, machines)
+				}),
+			huh.NewConfirm().
+				Key("done").
+				Title("Save machine details?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&editedMachineIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.editMachineForm.Init()
+}
+
+// saveEditedMachine sends the edited name and types, the menu keeps the old name if the lighthouse doesn't save them
+func saveEditedMachine(m *model) tea.Cmd {
+	machine := machineById(*m, m.selectedMachine.Id)
+	machine.Name = editedMachineName
+	machine.Types = editedMachineTypes
+	if updateMachine(machine).Id == "" {
+		return showToast(m, "Cannot save "+m.selectedMachine.Name+", check that the lighthouse is reachable")
+	}
+	m.selectedMachine.Name = machine.Name
+	return nil
+}
+
+// machineById returns the machine loaded for the Machines table
+func machineById(m model, machineId string) Machine {
+	for _, machine := range m.machines {
+		if machine.Id == machineId {
+			return machine
+		}
+	}
+	return Machine{Id: machineId}
+}
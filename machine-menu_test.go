@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestValidateMachineName(t *testing.T) {
+	machines := []Machine{{Id: "m1", Name: "builder"}, {Id: "m2", Name: "web-1"}}
+
+	tests := []struct {
+		name      string
+		machineId string
+		valid     bool
+	}{
+		{"web-2", "", true},
+		{"web-1", "", false},
+		{"", "", false},
+		{"web-1", "m2", true},
+		{"builder", "m2", false},
+	}
+	for _, test := range tests {
+		if err := validateMachineName(test.name, test.machineId, machines); (err == nil) != test.valid {
+			t.Errorf("validateMachineName(%q, %q) = %v, want valid %v", test.name, test.machineId, err, test.valid)
+		}
+	}
+}
+
+// A rename that the lighthouse rejects keeps the old name in the menu
+func TestSaveEditedMachine(t *testing.T) {
+	statusCode := http.StatusInternalServerError
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/machine" {
+			http.NotFound(w, r)
+			return
+		}
+		var machine Machine
+		json.NewDecoder(r.Body).Decode(&machine)
+		w.WriteHeader(statusCode)
+		if statusCode == http.StatusOK {
+			json.NewEncoder(w).Encode(machine)
+		}
+	}))
+
+	m := newModel()
+	m.machines = []Machine{{Id: "m1", Name: "web-1", Types: []string{MACHINE_TYPE_WORKLOAD}}}
+	m.selectedMachine = m.machines[0]
+	editedMachineName = "web-2"
+	editedMachineTypes = []string{MACHINE_TYPE_WORKLOAD}
+
+	if saveEditedMachine(&m) == nil || m.selectedMachine.Name != "web-1" {
+		t.Errorf("failed rename shows %s without an error", m.selectedMachine.Name)
+	}
+
+	statusCode = http.StatusOK
+	if saveEditedMachine(&m) != nil || m.selectedMachine.Name != "web-2" {
+		t.Errorf("saved rename shows %s, want web-2", m.selectedMachine.Name)
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"runtime"
@@ -11,6 +13,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,11 +33,41 @@ const SCREEN_TYPE_DEPLOYMENT_SCHEDULED = 15
 const SCREEN_TYPE_MACHINE_MENU = 16
 const SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION = 17
 const SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION = 18
+const SCREEN_TYPE_PROJECTS = 19
+const SCREEN_TYPE_PROJECT_FORM = 20
+const SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION = 21
+const SCREEN_TYPE_PROMOTE_ENVIRONMENT = 22
+const SCREEN_TYPE_PREVIEW_SETTINGS = 23
+const SCREEN_TYPE_COMPARE_ENVIRONMENT = 24
+const SCREEN_TYPE_ENVIRONMENT_DIFF = 25
+const SCREEN_TYPE_BULK_MENU = 26
+const SCREEN_TYPE_BULK_DELETE_CONFIRMATION = 27
+const SCREEN_TYPE_BULK_RUNNING = 28
+const SCREEN_TYPE_BULK_RESULT = 29
+const SCREEN_TYPE_SERVICE_KEYS = 30
+const SCREEN_TYPE_EDIT_MACHINE = 31
+const SCREEN_TYPE_DRAIN_CONFIRMATION = 32
+const SCREEN_TYPE_PROTECTED_ACTION = 33
+const SCREEN_TYPE_APPROVALS = 34
+const SCREEN_TYPE_SCHEDULE_DEPLOY = 35
+const SCREEN_TYPE_FREEZE_WINDOWS = 36
+const SCREEN_TYPE_CANCEL_SCHEDULED_DEPLOY = 37
+const SCREEN_TYPE_VOLUMES = 38
+const SCREEN_TYPE_NEW_DATABASE = 39
+const SCREEN_TYPE_DATABASE_MENU = 40
+const SCREEN_TYPE_LINK_ENVIRONMENTS = 41
+const SCREEN_TYPE_BACKUPS = 42
+const SCREEN_TYPE_BACKUP_POLICY = 43
+const SCREEN_TYPE_RESTORE_CONFIRMATION = 44
+const SCREEN_TYPE_BACKUP_TARGET = 45
 
 // Strings
 const ADD_ENVIRONMENT_STRING = "Add Environment"
 const MENU_EDIT = "Edit / Details"
 const MENU_DEPLOY = "Deploy"
+const MENU_CLONE = "Clone"
+const MENU_PROMOTE = "Promote"
+const MENU_COMPARE = "Compare"
 const MENU_DELETE = "Delete"
 const MENU_BACK = "Back"
 
@@ -91,36 +124,139 @@ type model struct {
 	list         list.Model
 	delegateKeys *delegateKeyMap
 
+	//Projects
+	projectList               table.Model
+	selectedProject           Project
+	editedProject             Project
+	projectDeleteBlocker      string //why the edited project can't be deleted, empty if it can
+	projectForm               *huh.Form
+	deleteProjectConfirmation textinput.Model
+	projectMachineIds         []string
+	usedMachineIds            []string
+
 	//Machines
 	machineList               table.Model
+	machines                  MachineMsg
+	selectedMachineIds        map[string]bool
 	machineMenu               list.Model
 	selectedMachine           Machine
 	deleteMachineConfirmation textinput.Model
+	machineFilter             tableFilter
+	editMachineForm           *huh.Form
+	drainMachine              DrainMachineMsg
+	drainedMachineIds         map[string]bool
+	machineDeleteImpact       MachineDeleteImpactMsg
+	drainConfirmation         textinput.Model
 
 	//Services
 	serviceList               table.Model
+	services                  ServicesMsg
+	serviceFilter             tableFilter
 	selectedService           Service
 	deleteServiceConfirmation textinput.Model
+	serviceKeys               ServiceKeysMsg
 
 	//New service
 	newServiceForm *huh.Form
 
+	//Preview environments
+	previewSettingsForm *huh.Form
+	previewService      Service
+
 	//Environments
-	environmentList       table.Model
-	envMenu               list.Model
-	selectedEnvironment   Environment
-	deleteEnvConfirmation textinput.Model
+	environmentList        table.Model
+	environments           EnvironmentsMsg
+	selectedEnvironmentIds map[string]bool
+	environmentFilter      tableFilter
+	envMenu                list.Model
+	selectedEnvironment    Environment
+	deleteEnvConfirmation  textinput.Model
+
+	//Protected environments
+	protectedAction       string
+	protectedConfirmation textinput.Model
+	approval              Approval
+	approvalHint          string
+	pendingApprovals      []Approval
+	approvalForm          *huh.Form
+
+	//Scheduled deployments and freeze windows
+	scheduledDeployments      []ScheduledDeployment
+	scheduleDeployForm        *huh.Form
+	freezeWindowsForm         *huh.Form
+	cancelScheduledDeployForm *huh.Form
+
+	//Volumes
+	volumesForm *huh.Form
+
+	//Databases
+	newDatabaseForm      *huh.Form
+	databaseMenu         list.Model
+	linkEnvironmentsForm *huh.Form
+	databaseLinkedNames  []string
+
+	//Backups
+	backupResource      backupResource
+	snapshots           SnapshotsMsg
+	snapshotList        table.Model
+	backupPolicyForm    *huh.Form
+	backupTargetForm    *huh.Form
+	restoreSnapshot     Snapshot
+	restoreConfirmation textinput.Model
 
 	//New environment
-	newEnvironmentForm *huh.Form
-	newEnvironmentHint string
+	newEnvironmentForm    *huh.Form
+	newEnvironmentHint    string
+	newEnvironmentActions []promptAction
+	clonedEnvironment     Environment
+
+	//Promote environment
+	promoteForm    *huh.Form
+	promoteSource  Environment
+	promoteTargets []Environment
+	promoteHint    string
+
+	//Compare environments
+	compareForm    *huh.Form
+	compareSource  Environment
+	compareTargets []Environment
+	compareDiff    string
+
+	//Bulk actions on selected environments or machines
+	bulkTarget       string
+	bulkMenu         list.Model
+	bulkAction       string
+	bulkConfirmation textinput.Model
+	bulkResult       string
+	//Environments deployed to machines selected for deletion
+	bulkDeleteEnvironments []machineEnvironment
+
+	//Deployments started from this CLI
+	deployments        map[string]*trackedDeployment
+	deploymentsPolling bool
+	deploymentHint     string
+
+	//Progress of the deployment on the Deployment Scheduled screen
+	deploymentProgress         DeploymentProgressMsg
+	deploymentProgressSequence int
+	previousDeploymentId       string
+	abortRequested             bool
+	spinner                    spinner.Model
+	spinnerRunning             bool
+	toast                      string
+	toastUntil                 time.Time
+
+	//Deletion that can be undone until the countdown ends
+	pendingDelete         *pendingDelete
+	pendingDeleteSequence int
 
 	screenWidth  int
 	screenHeight int
 
 	//New machine
-	newMachineForm     *huh.Form
-	newMachineJoinHint string
+	newMachineForm        *huh.Form
+	newMachineJoinHint    string
+	newMachineJoinActions []promptAction
 }
 
 var baseStyle = lipgloss.NewStyle().
@@ -129,15 +265,16 @@ var baseStyle = lipgloss.NewStyle().
 	Padding(2, 0)
 
 var (
-	newMachineTypes string
+	newMachineTypes []string
 	newMachineName  string
 	newMachineIsAdd bool
 )
 
 var (
-	newServiceName   string
-	newServiceGitURL string
-	newServiceIsAdd  bool
+	newServiceName      string
+	newServiceGitURL    string
+	newServiceProjectId string
+	newServiceIsAdd     bool
 )
 
 var (
@@ -145,8 +282,9 @@ var (
 	newEnvironmentBranchName string
 	newEnvironmentIsAdd      bool
 	newEnvironmentPort       string
-	newEnvironmentDomain     string
+	newEnvironmentDomains    string
 	newEnvironmentMachines   []string
+	newEnvironmentProtected  bool
 )
 
 type TickMsg time.Time
@@ -166,15 +304,14 @@ type NewEnvironmentMsg struct {
 	service Service
 }
 
-func newEnvironmentMsg(serviceId string, serviceName string) tea.Cmd {
+// newEnvironmentMsg adds the service typed in the Add Service form first if the service has no ID
+func newEnvironmentMsg(service Service) tea.Cmd {
 	return func() tea.Msg {
 		var msg NewEnvironmentMsg
-		if serviceId == "" {
-			msg.service = postService(newServiceName, newServiceGitURL)
+		if service.Id == "" {
+			msg.service = postService(newServiceFromForm())
 		} else {
-			msg.service.Id = serviceId
-			msg.service.Name = serviceName
-
+			msg.service = service
 		}
 		return msg
 	}
@@ -201,8 +338,43 @@ func editEnvironmentMsg(environmentId string, serviceId string) tea.Cmd {
 
 }
 
+type CloneEnvironmentMsg struct {
+	environment Environment
+}
+
+func cloneEnvironmentMsg(environmentId string, serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		var environments = getEnvironments(serviceId).(EnvironmentsMsg)
+		var msg CloneEnvironmentMsg
+
+		for _, environment := range environments {
+			if environment.Id == environmentId {
+				msg.environment = environment
+			}
+		}
+
+		return msg
+	}
+
+}
+
+type PromoteEnvironmentMsg struct {
+	environment  Environment
+	environments []Environment
+}
+
+func promoteEnvironmentMsg(environmentId string, serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		var msg PromoteEnvironmentMsg
+		msg.environment, msg.environments = getEnvironmentWithSiblings(environmentId, serviceId)
+		return msg
+	}
+
+}
+
 type MenuEnvironmentMsg struct {
 	environment Environment
+	approvals   []Approval
 }
 
 func menuEnvironmentMsg(envId string, envName string) tea.Cmd {
@@ -210,6 +382,7 @@ func menuEnvironmentMsg(envId string, envName string) tea.Cmd {
 		var msg MenuEnvironmentMsg
 		msg.environment.Id = envId
 		msg.environment.Name = envName
+		msg.approvals = pendingApprovals(envId)
 
 		return msg
 	}
@@ -246,11 +419,14 @@ func newModel() model {
 
 	// Make initial list of items
 	items := []list.Item{
+		projectMenuItem(Project{}),
 		item{title: "Getting Started", description: "How to deploy the first project"},
 		item{title: "Add Machine", description: "Add a new server or local machine"},
 		item{title: "Machines", description: "Manage servers and local machines"},
 		item{title: "Add Service", description: "Deploy a new service"},
+		item{title: MENU_ADD_DATABASE, description: "Provision Postgres or Redis on your machines"},
 		item{title: "Services", description: "Deploy and manage services and environments"},
+		item{title: MENU_BACKUP_STORAGE, description: "Where backups of volumes and databases are stored"},
 		item{title: "Docs", description: "Detailed documentation and examples"},
 	}
 
@@ -279,12 +455,53 @@ func newModel() model {
 	deleteServiceConfirmation.CharLimit = 156
 	deleteServiceConfirmation.Width = 20
 
+	//Setup drainConfirmation
+	drainConfirmation := textinput.New()
+	drainConfirmation.Focus()
+	drainConfirmation.CharLimit = 156
+	drainConfirmation.Width = 20
+
+	//Setup protectedConfirmation
+	protectedConfirmation := textinput.New()
+	protectedConfirmation.CharLimit = 156
+	protectedConfirmation.Width = 40
+
+	//Setup restoreConfirmation
+	restoreConfirmation := textinput.New()
+	restoreConfirmation.CharLimit = 156
+	restoreConfirmation.Width = 40
+
+	//Setup bulkConfirmation
+	bulkConfirmation := textinput.New()
+	bulkConfirmation.Focus()
+	bulkConfirmation.CharLimit = 156
+	bulkConfirmation.Width = 20
+
+	//Setup deleteProjectConfirmation
+	deleteProjectConfirmation := textinput.New()
+	deleteProjectConfirmation.Focus()
+	deleteProjectConfirmation.CharLimit = 156
+	deleteProjectConfirmation.Width = 20
+
 	model := model{
 		list:                      mainMenu,
 		delegateKeys:              delegateKeys,
 		deleteEnvConfirmation:     deleteEnvConfirmation,
 		deleteMachineConfirmation: deleteMachineConfirmation,
 		deleteServiceConfirmation: deleteServiceConfirmation,
+		deleteProjectConfirmation: deleteProjectConfirmation,
+		bulkConfirmation:          bulkConfirmation,
+		drainConfirmation:         drainConfirmation,
+		protectedConfirmation:     protectedConfirmation,
+		restoreConfirmation:       restoreConfirmation,
+		selectedMachineIds:        map[string]bool{},
+		drainedMachineIds:         map[string]bool{},
+		selectedEnvironmentIds:    map[string]bool{},
+		machineFilter:             newTableFilter(),
+		serviceFilter:             newTableFilter(),
+		environmentFilter:         newTableFilter(),
+		deployments:               map[string]*trackedDeployment{},
+		spinner:                   newDeploymentSpinner(),
 	}
 
 	return model
@@ -303,10 +520,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.screenWidth = msg.Width
 		m.screenHeight = msg.Height
 
-		h, v := appStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		resizeMainMenu(&m)
 
-		v, _ = listStyle.GetFrameSize()
+		v, _ := listStyle.GetFrameSize()
 		m.machineList.SetWidth(m.screenWidth - 2*v)
 		m.machineList.SetHeight(m.screenHeight - listTopHintHeght)
 
@@ -321,6 +537,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.environmentList.SetWidth(m.screenWidth - 2*v)
 		m.environmentList.SetHeight(m.screenHeight - listTopHintHeght)
 
+		m.projectList.SetWidth(m.screenWidth - 2*v)
+		m.projectList.SetHeight(m.screenHeight - listTopHintHeght)
+
 		if screenType == SCREEN_TYPE_ENV_MENU {
 			v, _ := listStyle.GetFrameSize()
 			m.envMenu.SetSize(m.screenWidth-2*v, m.screenHeight-listTopHintHeght)
@@ -336,29 +555,49 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case MainMenuMsg:
 		screenType = 1
 
+	case spinner.TickMsg:
+		if !isAnyDeploymentInProgress(m) {
+			m.spinnerRunning = false
+			break
+		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+		if screenType == SCREEN_TYPE_ENVIRONMENTS {
+			m.environmentList.SetRows(environmentRows(m))
+		}
+
+	case DeploymentsPolledMsg:
+		cmds = append(cmds, updateDeployments(&m, msg))
+
+	case ToastExpiredMsg:
+		if !time.Time(msg).Before(m.toastUntil) {
+			m.toast = ""
+			resizeMainMenu(&m)
+		}
+
 	case NewMachineMsg:
 		screenType = 3
 
-		newMachineTypes = "" //newMachineTypes[:0]
+		newMachineTypes = []string{MACHINE_TYPE_WORKLOAD}
 		newMachineName = ""
 		newMachineIsAdd = true
+		machines := msg.machines
+		if machines == nil {
+			machines = m.machines
+		}
 		m.newMachineForm = huh.NewForm(
 			huh.NewGroup(
-				huh.NewSelect[string]().
-					Title("Choose Machine Type").
-					Options(
-						huh.NewOption("Server", "workload"),
-						huh.NewOption("Local Machine", "local_machine"),
-					).
-					Value(&newMachineTypes),
+				huh.NewMultiSelect[string]().
+					Title("Choose Machine Types").
+					Options(machineTypeOptions()...).
+					Value(&newMachineTypes).
+					Validate(validateMachineTypes),
 
 				huh.NewInput().
 					Title("Machine Name").
 					Value(&newMachineName).
 					Validate(func(str string) error {
-						/*if str == "Frank" {
-						}*/
-						return nil
+						return validateMachineName(str, "", machines)
 					}),
 				huh.NewConfirm().
 					Key("done").
@@ -387,41 +626,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		screenType = 7
 
 		newServiceName = ""
-		newServiceGitURL = ""
+		newServiceProjectId = m.selectedProject.Id
 		newServiceIsAdd = true
-		m.newServiceForm = huh.NewForm(
-			huh.NewGroup(
-				huh.NewInput().
-					Title("Service Name").
-					Value(&newServiceName).
-					Validate(func(str string) error {
-						/*if str == "Frank" {
-						}*/
-						return nil
-					}),
-				huh.NewInput().
-					Title("Git clone URL").
-					Placeholder("get@...for private repos and https://... for public repos").
-					Value(&newServiceGitURL).
-					Validate(func(str string) error {
-						/*if str == "Frank" {
-						}*/
-						return nil
-					}),
-				huh.NewConfirm().
-					Key("done").
-					Title("Add a new service?").
-					Validate(func(v bool) error {
-						if !v {
-							screenType = 1
-						}
-						return nil
-					}).
-					Affirmative("Add").
-					Negative("Cancel").
-					Value(&newServiceIsAdd),
-			),
-		)
+		setServiceSourceForm()
+
+		serviceFields := []huh.Field{
+			huh.NewInput().
+				Title("Service Name").
+				Value(&newServiceName).
+				Validate(func(str string) error {
+					/*if str == "Frank" {
+					}*/
+					return nil
+				}),
+			serviceSourceField(),
+		}
+
+		confirmationFields := []huh.Field{}
+		if projectOptions := getProjectOptions(); projectOptions != nil {
+			confirmationFields = append(confirmationFields, huh.NewSelect[string]().
+				Title("Project").
+				Options(projectOptions...).
+				Value(&newServiceProjectId))
+		}
+
+		confirmationFields = append(confirmationFields, huh.NewConfirm().
+			Key("done").
+			Title("Add a new service?").
+			Validate(func(v bool) error {
+				if !v {
+					screenType = 1
+				}
+				return nil
+			}).
+			Affirmative("Add").
+			Negative("Cancel").
+			Value(&newServiceIsAdd))
+
+		//The Git URL or the image page is shown after the source is chosen
+		groups := append([]*huh.Group{huh.NewGroup(serviceFields...)}, serviceSourceGroups()...)
+		m.newServiceForm = huh.NewForm(append(groups, huh.NewGroup(confirmationFields...))...)
 
 		m.newServiceForm.Init()
 
@@ -432,23 +676,84 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newEnvironmentBranchName = ""
 		newEnvironmentIsAdd = true
 		newEnvironmentPort = ""
-		newEnvironmentDomain = ""
+		newEnvironmentDomains = ""
 		newEnvironmentMachines = newEnvironmentMachines[:0]
+		newEnvironmentProtected = false
+		setHealthCheckForm(Environment{})
+		setDeploymentStrategyForm(Environment{})
+		setResourcesForm(Environment{})
 
-		m.selectedService.Id = msg.service.Id
-		m.selectedService.Name = msg.service.Name
+		m.selectedService = msg.service
+		m.clonedEnvironment = Environment{}
 
 		machineOptions, _ := getMachineOptions()
 		createEnvironmentDetails(&m, machineOptions, "Add a new environment?", "Add")
 
+	case CloneEnvironmentMsg:
+		screenType = SCREEN_TYPE_NEW_ENVIRONMENT
+		machineOptions, machines := getMachineOptions()
+
+		newEnvironmentMachines = newEnvironmentMachines[:0]
+		for _, machine := range machines {
+			if slices.Contains(msg.environment.MachineIds, machine.Id) {
+				newEnvironmentMachines = append(newEnvironmentMachines, machine.Id)
+			}
+		}
+
+		newEnvironmentName = msg.environment.Name + "-copy"
+		newEnvironmentBranchName = msg.environment.Branch
+		newEnvironmentIsAdd = true
+		newEnvironmentPort = msg.environment.Port
+		//Domains of the original environment have to be renamed, see createEnvironmentDetails
+		newEnvironmentDomains = strings.Join(msg.environment.Domains, ", ")
+
+		newEnvironmentProtected = msg.environment.Protected
+		setHealthCheckForm(msg.environment)
+		setDeploymentStrategyForm(msg.environment)
+		setResourcesForm(msg.environment)
+
+		m.clonedEnvironment = msg.environment
+		createEnvironmentDetails(&m, machineOptions, "Clone "+msg.environment.Name+" to a new environment?", "Clone")
+
+	case PromoteEnvironmentMsg:
+		screenType = SCREEN_TYPE_PROMOTE_ENVIRONMENT
+		m.promoteSource = msg.environment
+		m.promoteTargets = msg.environments
+		m.promoteForm = nil
+
+		if msg.environment.LastDeploymentCommit == "" {
+			m.promoteHint = "\n " + msg.environment.Name + " has no deployed commit yet, deploy it first.\n Press ESC to return to the Environment menu"
+		} else if len(msg.environments) == 0 {
+			m.promoteHint = "\n This service has no other environments to promote to.\n Press ESC to return to the Environment menu"
+		} else {
+			m.promoteHint = ""
+			createPromoteForm(&m, msg.environment, msg.environments)
+		}
+
+	case CompareEnvironmentMsg:
+		screenType = SCREEN_TYPE_COMPARE_ENVIRONMENT
+		m.compareSource = msg.environment
+		m.compareTargets = msg.environments
+		m.compareForm = nil
+		m.compareDiff = ""
+
+		if len(msg.environments) == 0 {
+			m.compareDiff = newMachineHintTitleStyle.Render("\n This service has no other environments to compare with.\n Press ESC to return to the Environment menu")
+		} else {
+			createCompareForm(&m, msg.environment, msg.environments)
+		}
+
 	case EditEnvironmentMsg:
 		screenType = SCREEN_TYPE_EDIT_ENVIRONMENT
 		machineOptions, machines := getMachineOptions()
+		m.clonedEnvironment = Environment{}
+		//Fields that aren't in the form are sent back unchanged
+		m.selectedEnvironment = msg.environment
 
 		newEnvironmentMachines = newEnvironmentMachines[:0]
 		for _, machine := range machines {
 			if slices.Contains(msg.environment.MachineIds, machine.Id) {
-				newEnvironmentMachines = append(newEnvironmentMachines, machine.Name)
+				newEnvironmentMachines = append(newEnvironmentMachines, machine.Id)
 			}
 		}
 
@@ -456,7 +761,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newEnvironmentBranchName = msg.environment.Branch
 		newEnvironmentIsAdd = true
 		newEnvironmentPort = msg.environment.Port
-		newEnvironmentDomain = msg.environment.Domains[0]
+		newEnvironmentDomains = strings.Join(msg.environment.Domains, ", ")
+		newEnvironmentProtected = msg.environment.Protected
+		setHealthCheckForm(msg.environment)
+		setDeploymentStrategyForm(msg.environment)
+		setResourcesForm(msg.environment)
 
 		createEnvironmentDetails(&m, machineOptions, "Save environment details?", "Save")
 
@@ -464,21 +773,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		screenType = 9
 
 		//Get the first builder machine
-		machines := getMachines().(MachineMsg) //type asssertion
-		var machineBuilder Machine
-		for _, machine := range machines {
-			if slices.Contains(machine.Types, "builder") {
-				machineBuilder = machine
-				break
-			}
-		}
+		machineBuilder := getBuilderMachine()
+		m.newEnvironmentActions = nil
 
-		if len(machineBuilder.Domains) == 0 {
+		if isImageService(m.selectedService) {
+			//Images are pulled from the registry, the deploy key and the webhook aren't needed
+			m.newEnvironmentHint = imageEnvironmentHint(m.selectedService, machineBuilder, msg.Id)
+		} else if len(machineBuilder.Domains) == 0 {
 			m.newEnvironmentHint = "Before deploying this environment you should add at least one domain to the builder machine. Contact us at hey@turbocloud.dev iff you don't know how to do that."
 		} else {
 
-			sshKeyHint := "    To allow cloning the git repository from your build machine, you should add public SSH key below to GitHub, Bitbucket repository access/deploy keys (only read permission is required):\n\n" + codeHintStyle.Render(strings.Replace(machineBuilder.PublicSSHKey, "\n", "", -1)) + "\n\n"
-			webhookHint := "    To deploy after each Git push to a remote repository automatically, you should add a webhook below to GitHub (don't forget to select application/json in the Content-Type dropdown), Bitbucket repository webhooks:\n\n" + codeHintStyle.Render("https://"+machineBuilder.Domains[0]+"/deploy/"+m.selectedService.Id)
+			sshKeyHint := "    To allow cloning the git repository from your build machine, you should add public SSH key below to GitHub, Bitbucket repository access/deploy keys (only read permission is required):\n\n" + codeHintStyle.Render(deployKey(machineBuilder)) + "\n\n"
+			webhookHint := "    To deploy after each Git push to a remote repository automatically, you should add a webhook below to GitHub (don't forget to select application/json in the Content-Type dropdown), Bitbucket repository webhooks:\n\n" + codeHintStyle.Render(webhookURL(machineBuilder, m.selectedService.Id))
+			m.newEnvironmentActions = []promptAction{
+				copyAction("k", "Deploy key", deployKey(machineBuilder)),
+				copyAction("w", "Webhook URL", webhookURL(machineBuilder, m.selectedService.Id)),
+			}
 
 			//This public SSH key also can be found if ssh into your build machine (usually the first server you provisioned in this project) and run 'cat ~/.ssh/id_rsa.pub'`
 			m.newEnvironmentHint = sshKeyHint + webhookHint + "\n\n    Options to deploy:\n\n    • From the Environment menu: Main Menu → Services → Select Environment → Deploy\n    • Push any changes to the branch you specified in the previous step.\n    • Send a GET request to https://" + machineBuilder.Domains[0] + "/deploy/environment/" + msg.Id + "\n\n    To manage environments, go to Services and select a service from the list.\n\n"
@@ -493,7 +803,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		screenType = 4
 
-		m.newMachineJoinHint = "    • SSH into the new machine\n    • Copy and run the following command (shown only once):\n\n" + codeHintStyle.Render("    curl https://turbocloud.dev/setup | bash -s -- -j https://"+msg.newMachine.JoinURL) + "\n\n    • Once provisioning is complete, the status will show as 'Online' next to the machine in the Machines list.\n\n"
+		joinCommand := "curl https://turbocloud.dev/setup | bash -s -- -j https://" + msg.newMachine.JoinURL
+		m.newMachineJoinActions = []promptAction{copyAction("c", "Command", joinCommand)}
+		m.newMachineJoinHint = "    • SSH into the new machine\n    • Copy and run the following command (shown only once):\n\n" + codeHintStyle.Render("    "+joinCommand) + joinQRHint(&m, msg.newMachine, joinCommand) + "\n\n    • Once provisioning is complete, the status will show as 'Online' next to the machine in the Machines list.\n\n"
 		cmd := tea.Tick(100*time.Microsecond, func(t time.Time) tea.Msg {
 			return TickMsg(t)
 		})
@@ -504,6 +816,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// tell the Bubble Tea runtime we want to exit because we have nothing
 		// else to do. We'll still be able to render a final view with our
 		// status message.
+		if screenType != SCREEN_TYPE_MACHINES && m.selectedProject.Id != "" {
+			cmds = append(cmds, projectMachinesMsg(m.selectedProject.Id))
+		}
+
 		screenType = SCREEN_TYPE_MACHINES
 
 		//Reload machine list
@@ -519,26 +835,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			{Title: "CPU(%)", Width: 8},
 			{Title: "RAM(MB)", Width: 9},
 			{Title: "Disk(MB)", Width: 9},
+			{Title: "Volumes(MB)", Width: 11},
+			{Title: "", Width: 2},
 		}
 		//{"1", "Tokyo", "Japan", "37,274,000"}
-		rows := []table.Row{}
-
-		for index, machine := range msg {
+		m.machines = msg
+		for _, machine := range m.machines {
+			if !machine.Drained {
+				delete(m.drainedMachineIds, machine.Id)
+			}
+		}
+		rows := machineRows(m)
 
-			if selectedRow != nil && selectedRow[0] == machine.Id {
+		for index, row := range rows {
+			if selectedRow != nil && selectedRow[0] == row[0] {
 				indexToSelect = index
 			}
-			var tableRow []string
-			tableRow = append(tableRow, machine.Id)
-			tableRow = append(tableRow, machine.Name)
-			tableRow = append(tableRow, machine.VPNIp)
-			tableRow = append(tableRow, machine.PublicIp)
-			tableRow = append(tableRow, machine.Status)
-			tableRow = append(tableRow, machine.CPUUsage)
-			tableRow = append(tableRow, machine.MEMUsage)
-			tableRow = append(tableRow, machine.DiskUsage)
-
-			rows = append(rows, tableRow)
 		}
 
 		t := table.New(
@@ -575,7 +887,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return TickMsg(t)
 			}
 		})
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, cmd, drainProgressCmd(m))
 
 	case ServicesMsg:
 		// The server returned a status message. Save it to our model. Also
@@ -589,19 +901,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		columns := []table.Column{
 			{Title: "ID", Width: 8},
 			{Title: "Name", Width: 16},
-			{Title: "GitURL", Width: 50},
+			{Title: "Source", Width: 50},
+			{Title: "Health", Width: 20},
+			{Title: "Backup", Width: 26},
 		}
 		//{"1", "Tokyo", "Japan", "37,274,000"}
-		rows := []table.Row{}
-
-		for _, service := range msg {
-			var tableRow []string
-			tableRow = append(tableRow, service.Id)
-			tableRow = append(tableRow, service.Name)
-			tableRow = append(tableRow, service.GitURL)
-
-			rows = append(rows, tableRow)
-		}
+		m.services = msg
+		rows := serviceRows(m)
 
 		t := table.New(
 			table.WithColumns(columns),
@@ -639,35 +945,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		selectedRow := m.environmentList.SelectedRow()
 		indexToSelect := 0
 
+		//Services deployed from a registry image have no branches
+		branchTitle := "Branch"
+		if isImageService(m.selectedService) {
+			branchTitle = "Tag"
+		}
+
 		columns := []table.Column{
 			{Title: "ID", Width: 15},
 			{Title: "Name", Width: 16},
-			{Title: "Branch", Width: 16},
-			{Title: "Status", Width: 16},
+			{Title: branchTitle, Width: 16},
+			{Title: "Status", Width: 20},
+			{Title: "Health", Width: 20},
+			{Title: "", Width: 2},
+			{Title: "", Width: 2},
 		}
-		rows := []table.Row{}
 
-		var tableRow []string
-		tableRow = append(tableRow, ADD_ENVIRONMENT_STRING)
-		tableRow = append(tableRow, "")
-		tableRow = append(tableRow, "")
-		tableRow = append(tableRow, "")
+		m.environments = msg
+		rows := environmentRows(m)
 
-		rows = append(rows, tableRow)
-
-		for index, environment := range msg {
-
-			if selectedRow != nil && selectedRow[0] == environment.Id {
-				indexToSelect = index + 1
+		for index, row := range rows {
+			if selectedRow != nil && selectedRow[0] == row[0] {
+				indexToSelect = index
 			}
-
-			var tableRow []string
-			tableRow = append(tableRow, environment.Id)
-			tableRow = append(tableRow, environment.Name)
-			tableRow = append(tableRow, environment.Branch)
-			tableRow = append(tableRow, environment.LastDeploymentStatus)
-
-			rows = append(rows, tableRow)
 		}
 
 		t := table.New(
@@ -696,6 +996,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.environmentList.SetHeight(m.screenHeight - listTopHintHeght)
 
 		m.environmentList.MoveDown(indexToSelect)
+
+		if isAnyDeploymentInProgress(m) {
+			cmds = append(cmds, startSpinner(&m))
+		}
+
 		cmd := tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
 			if screenType == SCREEN_TYPE_ENVIRONMENTS {
 				return getEnvironments(m.selectedService.Id)
@@ -703,16 +1008,99 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return TickMsg(t)
 			}
 		})
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, cmd, getScheduledDeploymentsCmd(m.selectedService.Id))
+
+	case MachineDeleteImpactMsg:
+		screenType = SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION
+		m.machineDeleteImpact = msg
+		m.deleteMachineConfirmation.SetValue("")
+		m.deleteMachineConfirmation.Focus()
+
+	case PendingDeleteTickMsg:
+		cmds = append(cmds, updatePendingDelete(&m, msg))
+
+	case DeletedMsg:
+		cmds = append(cmds, showToast(&m, deletedToast(msg)), deletedListCmd(m, msg))
+
+	case DrainProgressMsg:
+		cmds = append(cmds, updateDrainProgress(&m, msg))
+
+	case ProjectMachinesMsg:
+		//Environments are loaded after the machines, the table is filtered again
+		if msg.projectId == m.selectedProject.Id {
+			m.projectMachineIds = msg.projectMachineIds
+			m.usedMachineIds = msg.usedMachineIds
+			if screenType == SCREEN_TYPE_MACHINES {
+				m.machineList.SetRows(machineRows(m))
+			}
+		}
+
+	case DrainMachineMsg:
+		screenType = SCREEN_TYPE_DRAIN_CONFIRMATION
+		m.drainMachine = msg
+		m.drainConfirmation.SetValue("")
+		m.drainConfirmation.Focus()
+
+	case ServiceKeysMsg:
+		screenType = SCREEN_TYPE_SERVICE_KEYS
+		m.serviceKeys = msg
+
+	case PreviewSettingsMsg:
+		if msg.err != nil {
+			return m, showToast(&m, "Preview settings can't be opened, "+msg.err.Error())
+		}
+		screenType = SCREEN_TYPE_PREVIEW_SETTINGS
+		m.previewService = msg.service
+		machineOptions, machines := getMachineOptions()
+		createPreviewSettingsForm(&m, msg.service, machineOptions, machines)
+
+	case BulkResultMsg:
+		screenType = SCREEN_TYPE_BULK_RESULT
+		m.bulkResult = bulkResultView(msg)
+
+		for _, result := range msg.results {
+			if result.err == nil && result.queuedAt.IsZero() && result.environment.Id != "" && (msg.action == MENU_DEPLOY || msg.action == MENU_REDEPLOY) {
+				cmds = append(cmds, trackDeployment(&m, result.environment))
+			}
+		}
+
+	case ProjectsMsg:
+		screenType = SCREEN_TYPE_PROJECTS
+		createProjectsTable(&m, msg)
 
 	case MenuEnvironmentMsg:
 		screenType = SCREEN_TYPE_ENV_MENU
 
 		envMenuItems := []list.Item{
 			item{title: MENU_DEPLOY, description: ""},
+			item{title: MENU_SCHEDULE_DEPLOY, description: ""},
+		}
+		if _, ok := m.deployments[msg.environment.Id]; ok || isDeploymentInProgress(environmentForDeployment(m, msg.environment.Id).LastDeploymentStatus) {
+			envMenuItems = append(envMenuItems, item{title: MENU_DEPLOYMENT_PROGRESS, description: ""})
+		}
+		if len(environmentScheduledDeployments(m, msg.environment.Id)) > 0 {
+			envMenuItems = append(envMenuItems, item{title: MENU_CANCEL_SCHEDULED_DEPLOY, description: ""})
+		}
+		envMenuItems = append(envMenuItems,
 			item{title: MENU_EDIT, description: ""},
+			item{title: MENU_FREEZE_WINDOWS, description: ""},
+			item{title: MENU_VOLUMES, description: ""},
+		)
+		for _, backupItem := range volumeBackupMenuItems(environmentForDeployment(m, msg.environment.Id)) {
+			envMenuItems = append(envMenuItems, backupItem)
+		}
+		envMenuItems = append(envMenuItems,
+			item{title: MENU_CLONE, description: ""},
+			item{title: MENU_PROMOTE, description: ""},
+			item{title: MENU_COMPARE, description: ""},
 			item{title: MENU_DELETE, description: ""},
 			item{title: MENU_BACK, description: ""},
+		)
+
+		//Requests of other users to deploy, edit or delete this protected environment
+		m.pendingApprovals = msg.approvals
+		if len(msg.approvals) > 0 {
+			envMenuItems = append([]list.Item{item{title: MENU_APPROVALS, description: ""}}, envMenuItems...)
 		}
 
 		m.envMenu = list.New(envMenuItems, envMenuItemDelegate{}, defaultWidth, listHeight)
@@ -724,11 +1112,70 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v, _ := listStyle.GetFrameSize()
 		m.envMenu.SetSize(m.screenWidth-2*v, m.screenHeight-listTopHintHeght)
 
+	case DeploymentProgressMsg:
+		//Polling stops when the screen is closed or another deployment is shown
+		if screenType != SCREEN_TYPE_DEPLOYMENT_SCHEDULED || msg.sequence != m.deploymentProgressSequence {
+			return m, nil
+		}
+		m.deploymentProgress = msg
+		if isDeploymentProgressFinished(m, msg) {
+			return m, nil
+		}
+		return m, pollDeploymentProgress(msg)
+
+	case ScheduledDeploymentsMsg:
+		m.scheduledDeployments = msg
+
+	case ApprovalMsg:
+		//Polling stops once the request is canceled or used
+		if screenType != SCREEN_TYPE_PROTECTED_ACTION || msg.id != m.approval.Id {
+			return m, nil
+		}
+		if msg.err == nil && isApproved(msg.approval) {
+			return runProtectedAction(m)
+		}
+		return m, pollApproval(msg.id)
+
+	case NewBackupTargetMsg:
+		screenType = SCREEN_TYPE_BACKUP_TARGET
+		createBackupTargetForm(&m)
+
+	case SnapshotsMsg:
+		//Polling stops once the Backups screen is left
+		if !isBackupScreen() || !isSameBackupResource(msg.resource, m.backupResource) {
+			return m, nil
+		}
+		msg.resource = m.backupResource
+		m.snapshots = msg
+		setRowsKeepingSelection(&m.snapshotList, snapshotRows(msg.snapshots))
+		if isSnapshotRunning(msg.snapshots) {
+			return m, pollSnapshots(m.backupResource)
+		}
+		return m, nil
+
+	case NewDatabaseMsg:
+		screenType = SCREEN_TYPE_NEW_DATABASE
+		createDatabaseForm(&m)
+
+	case NewDatabaseAddedMsg:
+		if msg.Id == "" {
+			screenType = 1
+			return m, showToast(&m, "Cannot add the database, check that the lighthouse is reachable")
+		}
+		return m, tea.Batch(showToast(&m, msg.Name+" is being provisioned, linked environments get credentials on the next deployment"), getServices)
+
 	case MenuMachineMsg:
 		screenType = SCREEN_TYPE_MACHINE_MENU
 
+		drainMenuItem := item{title: MENU_DRAIN, description: ""}
+		if machineById(m, msg.machine.Id).Drained {
+			drainMenuItem = item{title: MENU_UNDRAIN, description: ""}
+		}
+
 		machineMenuItems := []list.Item{
 			item{title: MENU_BACK, description: ""},
+			item{title: MENU_EDIT, description: ""},
+			drainMenuItem,
 			item{title: MENU_DELETE, description: ""},
 		}
 
@@ -746,7 +1193,40 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
+		//All keys go to the search input of the table while it's focused
+		if filter := activeTableFilter(&m); filter != nil && filter.input.Focused() {
+			return m, updateTableFilterInput(&m, filter, msg)
+		}
 		switch msg.String() {
+		case "/":
+			if filter := activeTableFilter(&m); filter != nil {
+				return m, filter.input.Focus()
+			}
+		case "s":
+			if filter := activeTableFilter(&m); filter != nil {
+				switch screenType {
+				case SCREEN_TYPE_MACHINES:
+					filter.sortBy = nextOption(machineSortColumns, filter.sortBy)
+				case SCREEN_TYPE_ENVIRONMENTS:
+					filter.sortBy = nextOption(environmentSortColumns, filter.sortBy)
+				default:
+					filter.sortBy = nextOption(serviceSortColumns, filter.sortBy)
+				}
+				refreshTableRows(&m)
+				return m, nil
+			}
+		case "f":
+			//f would scroll the table otherwise
+			if screenType == SCREEN_TYPE_MACHINES {
+				m.machineFilter.status = nextOption(machineStatuses(m), m.machineFilter.status)
+				refreshTableRows(&m)
+				return m, nil
+			}
+			if screenType == SCREEN_TYPE_ENVIRONMENTS {
+				m.environmentFilter.status = nextOption(environmentStatuses(m), m.environmentFilter.status)
+				refreshTableRows(&m)
+				return m, nil
+			}
 		case "d":
 			if screenType == SCREEN_TYPE_ENVIRONMENTS {
 				//Show Delete Service confirmation screen
@@ -755,9 +1235,116 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				screenType = SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION
 				return m, nil
 			}
+			if screenType == SCREEN_TYPE_PROJECTS && isProjectRow(m.projectList.SelectedRow()) {
+				//Show Delete Project confirmation screen
+				m.editedProject.Id = m.projectList.SelectedRow()[0]
+				m.editedProject.Name = m.projectList.SelectedRow()[1]
+				m.projectDeleteBlocker = projectDeleteBlocker(m.editedProject.Id)
+				m.deleteProjectConfirmation.SetValue("")
+				m.deleteProjectConfirmation.Focus()
+				screenType = SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION
+				return m, nil
+			}
+		case " ":
+			//Space selects rows for bulk actions, the table would scroll otherwise
+			if screenType == SCREEN_TYPE_ENVIRONMENTS {
+				if row := m.environmentList.SelectedRow(); row != nil && isEnvironmentRow(row) {
+					toggleSelection(m.selectedEnvironmentIds, row[0])
+					m.environmentList.SetRows(environmentRows(m))
+				}
+				return m, nil
+			}
+			if screenType == SCREEN_TYPE_MACHINES {
+				if row := m.machineList.SelectedRow(); row != nil {
+					toggleSelection(m.selectedMachineIds, row[0])
+					m.machineList.SetRows(machineRows(m))
+				}
+				return m, nil
+			}
+		case "a":
+			if screenType == SCREEN_TYPE_DEPLOYMENT_SCHEDULED && isDeploymentControllable(m) {
+				//Aborting needs a second press
+				if !m.abortRequested {
+					m.abortRequested = true
+					return m, nil
+				}
+				return m, controlDeploymentCmd(&m, DEPLOYMENT_ACTION_ABORT)
+			}
+			if screenType == SCREEN_TYPE_ENVIRONMENTS {
+				toggleSelectAll(m.selectedEnvironmentIds, environmentRowIds(m))
+				m.environmentList.SetRows(environmentRows(m))
+				return m, nil
+			}
+			if screenType == SCREEN_TYPE_MACHINES {
+				toggleSelectAll(m.selectedMachineIds, machineRowIds(m))
+				m.machineList.SetRows(machineRows(m))
+				return m, nil
+			}
+		case "x":
+			//Enter still opens the environment or the machine under the cursor
+			if screenType == SCREEN_TYPE_ENVIRONMENTS && len(selectedEnvironments(m)) > 0 {
+				m.bulkTarget = BULK_TARGET_ENVIRONMENTS
+				createBulkMenu(&m)
+				screenType = SCREEN_TYPE_BULK_MENU
+				return m, nil
+			}
+			if screenType == SCREEN_TYPE_MACHINES && len(selectedMachines(m)) > 0 {
+				m.bulkTarget = BULK_TARGET_MACHINES
+				createBulkMenu(&m)
+				screenType = SCREEN_TYPE_BULK_MENU
+				return m, nil
+			}
+		case "k":
+			if screenType == SCREEN_TYPE_SERVICE_KEYS && m.serviceKeys.deployKey != "" {
+				copyToClipboard(m.serviceKeys.deployKey)
+				return m, showToast(&m, "Deploy key copied to clipboard")
+			}
+		case "w":
+			if screenType == SCREEN_TYPE_ENVIRONMENTS && !isImageService(m.selectedService) {
+				//Show the webhook and deploy key of the service again, k would move the cursor up
+				return m, serviceKeysMsg(m.selectedService.Id)
+			}
+			if screenType == SCREEN_TYPE_SERVICE_KEYS && m.serviceKeys.webhookURL != "" {
+				copyToClipboard(m.serviceKeys.webhookURL)
+				return m, showToast(&m, "Webhook URL copied to clipboard")
+			}
+		case "u":
+			//u would scroll tables otherwise
+			if m.pendingDelete != nil && isUndoScreen() {
+				return m, undoDelete(&m)
+			}
+		case "p":
+			if screenType == SCREEN_TYPE_DEPLOYMENT_SCHEDULED && isDeploymentControllable(m) && !m.deploymentProgress.deployment.Paused {
+				return m, controlDeploymentCmd(&m, DEPLOYMENT_ACTION_PAUSE)
+			}
+			if screenType == SCREEN_TYPE_ENVIRONMENTS && !isImageService(m.selectedService) {
+				//Show preview environment settings of the service
+				return m, previewSettingsMsg(m.selectedService.Id)
+			}
+		case "r":
+			if screenType == SCREEN_TYPE_DEPLOYMENT_SCHEDULED && isDeploymentControllable(m) && m.deploymentProgress.deployment.Paused {
+				return m, runDeploymentAction(&m, DEPLOYMENT_ACTION_RESUME)
+			}
+			if screenType == SCREEN_TYPE_PROJECTS && isProjectRow(m.projectList.SelectedRow()) {
+				//Rename project
+				m.editedProject.Id = m.projectList.SelectedRow()[0]
+				m.editedProject.Name = m.projectList.SelectedRow()[1]
+				newProjectName = m.editedProject.Name
+				newProjectIsAdd = true
+				createProjectDetails(&m, "Save project?", "Save")
+				screenType = SCREEN_TYPE_PROJECT_FORM
+				return m, nil
+			}
 		// These keys should exit the program.
 		case "esc":
-			if screenType == 2 || screenType == 3 || screenType == 5 {
+			//The first ESC clears the search query of the table
+			if filter := activeTableFilter(&m); filter != nil && filter.input.Value() != "" {
+				filter.input.SetValue("")
+				refreshTableRows(&m)
+				return m, nil
+			}
+
+			if screenType == 2 || screenType == 3 || screenType == 5 || screenType == SCREEN_TYPE_PROJECTS {
 				screenType = 1
 				if m.newMachineForm != nil {
 					m.newMachineForm.State = huh.StateNormal
@@ -765,18 +1352,49 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+			if screenType == SCREEN_TYPE_BULK_MENU || screenType == SCREEN_TYPE_BULK_DELETE_CONFIRMATION || screenType == SCREEN_TYPE_BULK_RESULT {
+				return m, bulkListCmd(m)
+			}
+
+			if screenType == SCREEN_TYPE_PROJECT_FORM || screenType == SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION {
+				screenType = SCREEN_TYPE_PROJECTS
+				return m, nil
+			}
+
 			if screenType == SCREEN_TYPE_MACHINE_MENU {
 				screenType = SCREEN_TYPE_MACHINES
 				return m, nil
-			} else if screenType == SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION {
+			} else if screenType == SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION || screenType == SCREEN_TYPE_EDIT_MACHINE || screenType == SCREEN_TYPE_DRAIN_CONFIRMATION {
 				screenType = SCREEN_TYPE_MACHINE_MENU
 				return m, nil
 			}
 
-			if screenType == SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION {
+			if screenType == SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION && isDatabase(m.selectedService) || screenType == SCREEN_TYPE_LINK_ENVIRONMENTS {
+				screenType = SCREEN_TYPE_DATABASE_MENU
+				return m, nil
+			}
+			if screenType == SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION || screenType == SCREEN_TYPE_PREVIEW_SETTINGS || screenType == SCREEN_TYPE_SERVICE_KEYS {
 				screenType = SCREEN_TYPE_ENVIRONMENTS
 				return m, nil
 			}
+			if screenType == SCREEN_TYPE_DATABASE_MENU {
+				return m, getServices
+			}
+			if screenType == SCREEN_TYPE_BACKUPS {
+				return m, closeBackups(&m)
+			}
+			if screenType == SCREEN_TYPE_BACKUP_POLICY || screenType == SCREEN_TYPE_RESTORE_CONFIRMATION {
+				screenType = SCREEN_TYPE_BACKUPS
+				m.restoreConfirmation.Blur()
+				return m, snapshotsCmd(m.backupResource)
+			}
+			if screenType == SCREEN_TYPE_BACKUP_TARGET {
+				if m.backupTargetForm != nil {
+					m.backupTargetForm.State = huh.StateNormal
+				}
+				screenType = 1
+				return m, nil
+			}
 
 			if screenType == SCREEN_TYPE_ENVIRONMENTS {
 				screenType = 5
@@ -789,6 +1407,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				screenType = 1
 				return m, nil
 			}
+			if screenType == SCREEN_TYPE_NEW_DATABASE {
+				if m.newDatabaseForm != nil {
+					m.newDatabaseForm.State = huh.StateNormal
+				}
+				screenType = 1
+				return m, nil
+			}
 			if screenType == SCREEN_TYPE_NEW_ENVIRONMENT {
 				if m.newEnvironmentForm != nil {
 					m.newEnvironmentForm.State = huh.StateNormal
@@ -799,7 +1424,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if screenType == SCREEN_TYPE_ENV_MENU {
 				screenType = SCREEN_TYPE_ENVIRONMENTS
 				return m, nil
-			} else if screenType == SCREEN_TYPE_ENV_DELETE_CONFIRMATION {
+			} else if screenType == SCREEN_TYPE_PROTECTED_ACTION {
+				cancelApproval(&m)
+				if isDeploymentAction(m.protectedAction) {
+					//Back to the progress of the deployment, polling stopped when it was left
+					screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+					return m, showDeploymentProgress(&m, m.selectedEnvironment.Id, "")
+				}
+				if m.protectedAction == PROTECTED_ACTION_PROMOTE {
+					m.selectedEnvironment = m.promoteSource
+				}
+				screenType = SCREEN_TYPE_ENV_MENU
+				return m, nil
+			} else if screenType == SCREEN_TYPE_ENV_DELETE_CONFIRMATION || screenType == SCREEN_TYPE_APPROVALS || screenType == SCREEN_TYPE_SCHEDULE_DEPLOY || screenType == SCREEN_TYPE_FREEZE_WINDOWS || screenType == SCREEN_TYPE_VOLUMES || screenType == SCREEN_TYPE_CANCEL_SCHEDULED_DEPLOY || screenType == SCREEN_TYPE_PROMOTE_ENVIRONMENT || screenType == SCREEN_TYPE_COMPARE_ENVIRONMENT || screenType == SCREEN_TYPE_ENVIRONMENT_DIFF {
 				screenType = SCREEN_TYPE_ENV_MENU
 				return m, nil
 			}
@@ -808,7 +1445,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case "left":
-			if screenType == 2 || screenType == 5 {
+			if screenType == 2 || screenType == 5 || screenType == SCREEN_TYPE_PROJECTS {
 				screenType = 1
 				return m, nil
 			}
@@ -816,7 +1453,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				screenType = 5
 				return m, nil
 			}
-			if screenType == SCREEN_TYPE_ENV_MENU {
+			if screenType == SCREEN_TYPE_ENV_MENU || screenType == SCREEN_TYPE_SERVICE_KEYS {
 				screenType = SCREEN_TYPE_ENVIRONMENTS
 				return m, nil
 			}
@@ -824,13 +1461,97 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				screenType = SCREEN_TYPE_MACHINES
 				return m, nil
 			}
+			if screenType == SCREEN_TYPE_DATABASE_MENU {
+				return m, getServices
+			}
+			if screenType == SCREEN_TYPE_BACKUPS {
+				return m, closeBackups(&m)
+			}
 		case "enter":
-			if screenType == 5 {
+			if screenType == SCREEN_TYPE_PROJECTS {
+				//A project has been selected
+				selectedRow := m.projectList.SelectedRow()
+				if selectedRow == nil {
+					return m, nil
+				}
+				if selectedRow[0] == ADD_PROJECT_STRING {
+					m.editedProject = Project{}
+					newProjectName = ""
+					newProjectIsAdd = true
+					createProjectDetails(&m, "Add a new project?", "Add")
+					screenType = SCREEN_TYPE_PROJECT_FORM
+					return m, nil
+				} else if selectedRow[0] == ALL_PROJECTS_STRING {
+					selectProject(&m, Project{})
+				} else {
+					selectProject(&m, Project{Id: selectedRow[0], Name: selectedRow[1]})
+				}
+				screenType = 1
+				return m, nil
+			} else if screenType == SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION {
+				if m.projectDeleteBlocker == "" && strings.ToLower(m.deleteProjectConfirmation.Value()) == "y" {
+					if !deleteProject(m.editedProject.Id) {
+						return m, tea.Batch(showToast(&m, "Cannot delete "+m.editedProject.Name+", check that the lighthouse is reachable"), getProjects)
+					}
+					if m.selectedProject.Id == m.editedProject.Id {
+						selectProject(&m, Project{})
+					}
+					return m, getProjects
+				}
+			} else if screenType == SCREEN_TYPE_BULK_MENU {
+				switch m.bulkMenu.SelectedItem().(item).title {
+				case MENU_DEPLOY, MENU_REDEPLOY:
+					items, err := bulkItems(m, m.bulkMenu.SelectedItem().(item).title)
+					if err != nil {
+						return m, showToast(&m, "Cannot find environments on the selected machines, "+err.Error())
+					}
+					m.bulkAction = m.bulkMenu.SelectedItem().(item).title
+					screenType = SCREEN_TYPE_BULK_RUNNING
+					return m, runBulkAction(m.bulkAction, m.bulkTarget, items)
+				case MENU_DELETE:
+					m.bulkAction = MENU_DELETE
+					m.bulkDeleteEnvironments = nil
+					if m.bulkTarget == BULK_TARGET_MACHINES {
+						environments, err := getMachineEnvironments(slices.Collect(maps.Keys(m.selectedMachineIds)))
+						if err != nil {
+							return m, showToast(&m, "Machines can't be deleted, "+err.Error()+" to check what depends on them")
+						}
+						m.bulkDeleteEnvironments = environments
+					}
+					m.bulkConfirmation.SetValue("")
+					m.bulkConfirmation.Focus()
+					screenType = SCREEN_TYPE_BULK_DELETE_CONFIRMATION
+					return m, nil
+				case MENU_CLEAR_SELECTION:
+					clear(m.selectedEnvironmentIds)
+					clear(m.selectedMachineIds)
+				}
+				return m, bulkListCmd(m)
+			} else if screenType == SCREEN_TYPE_BULK_DELETE_CONFIRMATION {
+				//Deletions are held back like single deletions and can be undone
+				if isConfirmed(m.bulkConfirmation.Value(), bulkDeletePhrase(m)) && len(bulkDeleteBlockedTypes(m)) == 0 {
+					return m, bulkDelete(&m)
+				}
+			} else if screenType == SCREEN_TYPE_BULK_RESULT {
+				return m, bulkListCmd(m)
+			} else if screenType == 5 {
+				if m.serviceList.SelectedRow() == nil {
+					return m, nil
+				}
 				//A service has been selected
-				m.selectedService.Id = m.serviceList.SelectedRow()[0]
+				m.selectedService = serviceById(m, m.serviceList.SelectedRow()[0])
 				m.selectedService.Name = m.serviceList.SelectedRow()[1]
+				if isDatabase(m.selectedService) {
+					createDatabaseMenu(&m)
+					screenType = SCREEN_TYPE_DATABASE_MENU
+					return m, nil
+				}
+				clear(m.selectedEnvironmentIds)
 				return m, getEnvironmentsCmd(m.selectedService.Id)
 			} else if screenType == SCREEN_TYPE_MACHINES {
+				if m.machineList.SelectedRow() == nil {
+					return m, nil
+				}
 				//A new machine has been selected
 				cmds = append(cmds, menuMachineMsg(m.machineList.SelectedRow()[0], m.machineList.SelectedRow()[1]))
 				m.selectedMachine.Id = m.machineList.SelectedRow()[0]
@@ -841,18 +1562,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					//Go back
 					screenType = SCREEN_TYPE_MACHINES
 					return m, nil
+				} else if m.machineMenu.SelectedItem().(item).title == MENU_EDIT {
+					//Rename the machine or change its roles without re-provisioning
+					createMachineDetails(&m, machineById(m, m.selectedMachine.Id))
+					screenType = SCREEN_TYPE_EDIT_MACHINE
+					return m, nil
+				} else if m.machineMenu.SelectedItem().(item).title == MENU_DRAIN {
+					//List environments that lose the machine before confirming
+					return m, drainMachineMsg(machineById(m, m.selectedMachine.Id))
+				} else if m.machineMenu.SelectedItem().(item).title == MENU_UNDRAIN {
+					if !setMachineDrained(m.selectedMachine.Id, false) {
+						return m, showToast(&m, "Cannot undrain "+m.selectedMachine.Name)
+					}
+					return m, tea.Batch(showToast(&m, m.selectedMachine.Name+" is back in load balancing and deployments"), getMachines)
 				} else if m.machineMenu.SelectedItem().(item).title == MENU_DELETE {
-					//Delete environment
-					m.deleteMachineConfirmation.SetValue("")
-					m.deleteMachineConfirmation.Focus()
-					screenType = SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION
+					//Show what depends on the machine before confirming
+					return m, machineDeleteImpactMsg(m.selectedMachine.Id)
+				}
+
+			} else if screenType == SCREEN_TYPE_DATABASE_MENU {
+				switch m.databaseMenu.SelectedItem().(item).title {
+				case MENU_BACK:
+					return m, getServices
+				case MENU_LINK_ENVIRONMENTS:
+					screenType = SCREEN_TYPE_LINK_ENVIRONMENTS
+					createLinkEnvironmentsForm(&m)
+					return m, nil
+				case MENU_BACKUPS:
+					return m, showBackups(&m, databaseBackupResource(m.selectedService))
+				case MENU_DELETE:
+					//Linked environments are shown before confirming, the database has no environments of its own
+					m.environments = nil
+					m.databaseLinkedNames = linkedEnvironmentNames(m)
+					m.deleteServiceConfirmation.SetValue("")
+					m.deleteServiceConfirmation.Focus()
+					screenType = SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION
 					return m, nil
 				}
 
 			} else if screenType == SCREEN_TYPE_ENVIRONMENTS {
 				//A new environment has been selected
-				if m.environmentList.SelectedRow()[0] == ADD_ENVIRONMENT_STRING {
-					cmds = append(cmds, newEnvironmentMsg(m.serviceList.SelectedRow()[0], m.serviceList.SelectedRow()[1]))
+				if m.environmentList.SelectedRow()[0] == "" || m.environmentList.SelectedRow()[0] == PREVIEW_ENVIRONMENTS_STRING {
+					return m, nil
+				} else if m.environmentList.SelectedRow()[0] == ADD_ENVIRONMENT_STRING {
+					cmds = append(cmds, newEnvironmentMsg(m.selectedService))
 				} else {
 					cmds = append(cmds, menuEnvironmentMsg(m.environmentList.SelectedRow()[0], m.environmentList.SelectedRow()[1]))
 					m.selectedEnvironment.Id = m.environmentList.SelectedRow()[0]
@@ -860,54 +1613,101 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 			} else if screenType == SCREEN_TYPE_ENV_MENU {
-				//A new environment has been selected
-				if m.envMenu.SelectedItem().(item).title == MENU_BACK {
-					//Go back
-					screenType = SCREEN_TYPE_ENVIRONMENTS
-					return m, nil
-				} else if m.envMenu.SelectedItem().(item).title == MENU_DEPLOY {
-					//Deploy
-					screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
-					deployEnvironment(m.selectedEnvironment.Id)
-					return m, nil
-				} else if m.envMenu.SelectedItem().(item).title == MENU_EDIT {
-					//Edit environment
-					cmds = append(cmds, editEnvironmentMsg(m.selectedEnvironment.Id, m.selectedService.Id))
-				} else if m.envMenu.SelectedItem().(item).title == MENU_DELETE {
-					//Delete environment
-					m.deleteEnvConfirmation.SetValue("")
-					m.deleteEnvConfirmation.Focus()
-					screenType = SCREEN_TYPE_ENV_DELETE_CONFIRMATION
+				//Protected environments require a confirmation phrase or an approval first
+				action := m.envMenu.SelectedItem().(item).title
+				if needsProtectedConfirmation(m, action) {
+					startProtectedAction(&m, action)
 					return m, nil
 				}
+				return m, runEnvironmentMenuAction(&m, action)
 
 			} else if screenType == SCREEN_TYPE_ENV_DELETE_CONFIRMATION {
 				//A new environment has been selected
-				if strings.ToLower(m.deleteEnvConfirmation.Value()) == "y" {
-					deleteEnvironment(m.selectedEnvironment.Id)
-					return m, getEnvironmentsCmd(m.selectedService.Id)
+				if isConfirmed(m.deleteEnvConfirmation.Value(), environmentDeletePhrase(m)) {
+					return m, tea.Batch(scheduleDelete(&m, DELETE_TARGET_ENVIRONMENT, m.selectedEnvironment.Id, m.selectedEnvironment.Name, m.selectedService.Id), getEnvironmentsCmd(m.selectedService.Id))
 				}
 
-			} else if screenType == SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION {
+			} else if screenType == SCREEN_TYPE_BACKUPS {
+				snapshot, ok := selectedSnapshot(m)
+				if !ok {
+					return m, nil
+				}
+				if snapshot.Status != SNAPSHOT_STATUS_COMPLETED {
+					return m, showToast(&m, "Only completed snapshots can be restored")
+				}
+				m.restoreSnapshot = snapshot
+				m.restoreConfirmation.SetValue("")
+				m.restoreConfirmation.Focus()
+				screenType = SCREEN_TYPE_RESTORE_CONFIRMATION
+				return m, nil
+
+			} else if screenType == SCREEN_TYPE_RESTORE_CONFIRMATION {
+				if isConfirmed(m.restoreConfirmation.Value(), confirmationPhrase(true, m.backupResource.name)) {
+					m.restoreConfirmation.Blur()
+					screenType = SCREEN_TYPE_BACKUPS
+					if !restoreSnapshot(m.restoreSnapshot.Id) {
+						return m, showToast(&m, "Cannot restore "+m.backupResource.name+", check that the lighthouse is reachable")
+					}
+					return m, tea.Batch(showToast(&m, "Restoring "+m.backupResource.name+" from "+formatScheduleTime(m.restoreSnapshot.CreatedAt)), snapshotsCmd(m.backupResource))
+				}
+
+			} else if screenType == SCREEN_TYPE_PROTECTED_ACTION {
+				if isConfirmed(m.protectedConfirmation.Value(), protectedActionPhrase(m)) {
+					return runProtectedAction(m)
+				}
+
+			} else if screenType == SCREEN_TYPE_DRAIN_CONFIRMATION {
+				if strings.ToLower(m.drainConfirmation.Value()) == "y" && m.drainMachine.err == nil {
+					if !setMachineDrained(m.drainMachine.machine.Id, true) {
+						return m, tea.Batch(showToast(&m, "Cannot drain "+m.drainMachine.machine.Name), getMachines)
+					}
+					return m, tea.Batch(showToast(&m, m.drainMachine.machine.Name+" is draining, its status changes to Drained once running deployments finish"), getMachines)
+				}
+
+			} else if screenType == SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION {
 				//A new environment has been selected
-				if strings.ToLower(m.deleteMachineConfirmation.Value()) == "y" {
-					deleteMachine(m.selectedMachine.Id)
-					return m, getMachines
+				if isConfirmed(m.deleteMachineConfirmation.Value(), machineDeletePhrase(m)) && !isMachineDeleteBlocked(m.machineDeleteImpact) {
+					return m, tea.Batch(scheduleDelete(&m, DELETE_TARGET_MACHINE, m.selectedMachine.Id, m.selectedMachine.Name, ""), getMachines)
 				}
 
+			} else if screenType == SCREEN_TYPE_ENVIRONMENT_DIFF {
+				screenType = SCREEN_TYPE_ENV_MENU
+				return m, nil
 			} else if screenType == SCREEN_TYPE_DEPLOYMENT_SCHEDULED {
 				screenType = SCREEN_TYPE_ENVIRONMENTS
 				return m, getEnvironmentsCmd(m.selectedService.Id)
 			} else if screenType == SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION {
 				//A new environment has been selected
-				if strings.ToLower(m.deleteServiceConfirmation.Value()) == "y" {
-					deleteService(m.selectedService.Id)
+				if isConfirmed(m.deleteServiceConfirmation.Value(), serviceDeletePhrase(m)) {
+					cmd := scheduleDelete(&m, DELETE_TARGET_SERVICE, m.selectedService.Id, m.selectedService.Name, "")
 					m.selectedService.Id = ""
-					return m, getServices
+					return m, tea.Batch(cmd, getServices)
 				}
 
 			}
+		case "b":
+			if screenType == SCREEN_TYPE_BACKUPS {
+				//Back up now, outside of the schedule
+				if !postSnapshot(m.backupResource.resourceType, m.backupResource.resourceId, m.backupResource.volumeName) {
+					return m, showToast(&m, "Cannot back up "+m.backupResource.name+", check the backup storage and that the lighthouse is reachable")
+				}
+				return m, tea.Batch(showToast(&m, "Backup of "+m.backupResource.name+" started"), snapshotsCmd(m.backupResource))
+			}
+		case "e":
+			if screenType == SCREEN_TYPE_BACKUPS {
+				screenType = SCREEN_TYPE_BACKUP_POLICY
+				createBackupPolicyForm(&m)
+				return m, nil
+			}
+		case "ctrl+r":
+			if screenType == SCREEN_TYPE_PROTECTED_ACTION {
+				return m, requestApproval(&m)
+			}
 		case "ctrl+c":
+			//The confirmed deletion is sent right away instead of being lost
+			if m.pendingDelete != nil {
+				runDelete(*m.pendingDelete)()
+			}
 			return m, tea.Quit
 
 		}
@@ -973,7 +1773,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else if m.newServiceForm.State == huh.StateCompleted {
 			m.newServiceForm.State = huh.StateNormal
 			if newServiceIsAdd {
-				cmds = append(cmds, newEnvironmentMsg("", ""))
+				cmds = append(cmds, newEnvironmentMsg(Service{}))
 			} else {
 				screenType = 1
 			}
@@ -981,6 +1781,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	}
 
+	if screenType == SCREEN_TYPE_NEW_DATABASE {
+		form, cmd := m.newDatabaseForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.newDatabaseForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.newDatabaseForm.State == huh.StateAborted {
+			m.newDatabaseForm.State = huh.StateNormal
+			screenType = 1
+		} else if m.newDatabaseForm.State == huh.StateCompleted {
+			m.newDatabaseForm.State = huh.StateNormal
+			if newDatabaseIsAdd {
+				cmds = append(cmds, addDatabase)
+			} else {
+				screenType = 1
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_LINK_ENVIRONMENTS && m.linkEnvironmentsForm != nil {
+		form, cmd := m.linkEnvironmentsForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.linkEnvironmentsForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.linkEnvironmentsForm.State == huh.StateAborted {
+			m.linkEnvironmentsForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_DATABASE_MENU
+		} else if m.linkEnvironmentsForm.State == huh.StateCompleted {
+			m.linkEnvironmentsForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_DATABASE_MENU
+			if linkIsSave {
+				if updateDatabaseLinks(m.selectedService.Id, linkedEnvironmentIds) {
+					m.selectedService.LinkedEnvironmentIds = linkedEnvironmentIds
+					cmds = append(cmds, showToast(&m, "Linked environments saved, they get credentials on the next deployment"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot save linked environments, check that the lighthouse is reachable"))
+				}
+			}
+		}
+	}
+
 	if screenType == SCREEN_TYPE_NEW_ENVIRONMENT || screenType == SCREEN_TYPE_EDIT_ENVIRONMENT {
 		form, cmd := m.newEnvironmentForm.Update(msg)
 		if f, ok := form.(*huh.Form); ok {
@@ -1001,15 +1847,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					newEnvironment.ServiceId = m.selectedService.Id
 					newEnvironment.Name = newEnvironmentName
 					newEnvironment.Branch = newEnvironmentBranchName
-					newEnvironment.Domains = append(newEnvironment.Domains, newEnvironmentDomain)
+					newEnvironment.Domains = parseDomains(newEnvironmentDomains)
 					newEnvironment.Port = newEnvironmentPort
 					newEnvironment.GitTag = ""
+					newEnvironment.EnvironmentVariables = m.clonedEnvironment.EnvironmentVariables
+					newEnvironment.Protected = newEnvironmentProtected
+					newEnvironment.HealthCheck = healthCheckFromForm()
+					newEnvironment.AutoRollback = newEnvironmentAutoRollback
+					setDeploymentStrategy(&newEnvironment)
+					setResources(&newEnvironment)
+					newEnvironment.FreezeWindows = m.clonedEnvironment.FreezeWindows
+					newEnvironment.Volumes = m.clonedEnvironment.Volumes
+					newEnvironment.FreezeMode = m.clonedEnvironment.FreezeMode
 					//Get Machine Ids to deploy
 					machines := getMachines().(MachineMsg) //type asssertion
 					machineIds := []string{}
 
 					for _, machine := range machines {
-						if slices.Contains(newEnvironmentMachines, machine.Name) {
+						if slices.Contains(newEnvironmentMachines, machine.Id) {
 							machineIds = append(machineIds, machine.Id)
 						}
 					}
@@ -1028,19 +1883,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.newEnvironmentForm.State = huh.StateNormal
 				if newEnvironmentIsAdd {
 					//Send a request to create a new environment
-					var editedEnvironment Environment
-					editedEnvironment.Id = m.selectedEnvironment.Id
+					editedEnvironment := m.selectedEnvironment
 					editedEnvironment.Name = newEnvironmentName
 					editedEnvironment.Branch = newEnvironmentBranchName
-					editedEnvironment.Domains = append(editedEnvironment.Domains, newEnvironmentDomain)
+					editedEnvironment.Domains = parseDomains(newEnvironmentDomains)
 					editedEnvironment.Port = newEnvironmentPort
 					editedEnvironment.GitTag = ""
+					editedEnvironment.Protected = newEnvironmentProtected
+					editedEnvironment.HealthCheck = healthCheckFromForm()
+					editedEnvironment.AutoRollback = newEnvironmentAutoRollback
+					setDeploymentStrategy(&editedEnvironment)
+					setResources(&editedEnvironment)
 					//Get Machine Ids to deploy
 					machines := getMachines().(MachineMsg) //type asssertion
 					machineIds := []string{}
 
 					for _, machine := range machines {
-						if slices.Contains(newEnvironmentMachines, machine.Name) {
+						if slices.Contains(newEnvironmentMachines, machine.Id) {
 							machineIds = append(machineIds, machine.Id)
 						}
 					}
@@ -1056,6 +1915,283 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	}
 
+	if screenType == SCREEN_TYPE_PREVIEW_SETTINGS {
+		form, cmd := m.previewSettingsForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.previewSettingsForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.previewSettingsForm.State == huh.StateAborted {
+			m.previewSettingsForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENVIRONMENTS
+		} else if m.previewSettingsForm.State == huh.StateCompleted {
+			m.previewSettingsForm.State = huh.StateNormal
+			if previewIsSave {
+				savePreviewSettings(m.previewService)
+			}
+			cmds = append(cmds, getEnvironmentsCmd(m.selectedService.Id))
+		}
+	}
+
+	if screenType == SCREEN_TYPE_BULK_MENU {
+		newList, cmd := m.bulkMenu.Update(msg)
+		m.bulkMenu = newList
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_BULK_DELETE_CONFIRMATION {
+		m.bulkConfirmation, cmd = m.bulkConfirmation.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_PROJECTS {
+		m.projectList, cmd = m.projectList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_EDIT_MACHINE {
+		form, cmd := m.editMachineForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.editMachineForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.editMachineForm.State == huh.StateAborted {
+			m.editMachineForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_MACHINE_MENU
+		} else if m.editMachineForm.State == huh.StateCompleted {
+			m.editMachineForm.State = huh.StateNormal
+			if editedMachineIsSave {
+				cmds = append(cmds, saveEditedMachine(&m))
+			}
+			cmds = append(cmds, getMachines)
+		}
+	}
+
+	if screenType == SCREEN_TYPE_PROJECT_FORM {
+		form, cmd := m.projectForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.projectForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.projectForm.State == huh.StateAborted {
+			m.projectForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_PROJECTS
+		} else if m.projectForm.State == huh.StateCompleted {
+			m.projectForm.State = huh.StateNormal
+			if newProjectIsAdd {
+				if m.editedProject.Id == "" {
+					postProject(newProjectName)
+				} else {
+					m.editedProject.Name = newProjectName
+					updateProject(m.editedProject)
+					if m.selectedProject.Id == m.editedProject.Id {
+						selectProject(&m, m.editedProject)
+					}
+				}
+			}
+			cmds = append(cmds, getProjects)
+		}
+	}
+
+	if screenType == SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION {
+		m.deleteProjectConfirmation, cmd = m.deleteProjectConfirmation.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_SCHEDULE_DEPLOY && m.scheduleDeployForm != nil {
+		form, cmd := m.scheduleDeployForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.scheduleDeployForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.scheduleDeployForm.State == huh.StateAborted {
+			m.scheduleDeployForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.scheduleDeployForm.State == huh.StateCompleted {
+			m.scheduleDeployForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+			if scheduleDeployIsConfirmed {
+				environment := environmentForDeployment(m, m.selectedEnvironment.Id)
+				scheduledAt, err := parseScheduleTime(environment, scheduleDeployAt)
+				if err == nil && postScheduledDeployment(environment.Id, "", scheduledAt) {
+					cmds = append(cmds, showToast(&m, environment.Name+" will be deployed "+formatScheduleTime(scheduledAt)))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot schedule the deployment, check that the lighthouse is reachable"))
+				}
+				cmds = append(cmds, getEnvironmentsCmd(m.selectedService.Id))
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_CANCEL_SCHEDULED_DEPLOY && m.cancelScheduledDeployForm != nil {
+		form, cmd := m.cancelScheduledDeployForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.cancelScheduledDeployForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.cancelScheduledDeployForm.State == huh.StateAborted {
+			m.cancelScheduledDeployForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.cancelScheduledDeployForm.State == huh.StateCompleted {
+			m.cancelScheduledDeployForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+			if cancelScheduledIsConfirmed {
+				if cancelScheduledDeploymentId != "" && deleteScheduledDeployment(cancelScheduledDeploymentId) {
+					cmds = append(cmds, showToast(&m, "Scheduled deployment canceled"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot cancel the deployment, check that the lighthouse is reachable"))
+				}
+				cmds = append(cmds, getEnvironmentsCmd(m.selectedService.Id))
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_FREEZE_WINDOWS && m.freezeWindowsForm != nil {
+		form, cmd := m.freezeWindowsForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.freezeWindowsForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.freezeWindowsForm.State == huh.StateAborted {
+			m.freezeWindowsForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.freezeWindowsForm.State == huh.StateCompleted {
+			m.freezeWindowsForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+			if freezeIsSave {
+				windows, _ := parseFreezeWindows(freezeWindows)
+				if updateFreezeWindows(m.selectedEnvironment.Id, freezeMode, windows) {
+					cmds = append(cmds, showToast(&m, "Freeze windows saved"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot save freeze windows, check that the lighthouse is reachable"))
+				}
+				cmds = append(cmds, getEnvironmentsCmd(m.selectedService.Id))
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_VOLUMES && m.volumesForm != nil {
+		form, cmd := m.volumesForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.volumesForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.volumesForm.State == huh.StateAborted {
+			m.volumesForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.volumesForm.State == huh.StateCompleted {
+			m.volumesForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+			if volumesIsSave {
+				parsedVolumes, _ := parseVolumes(volumes)
+				keepVolumeBackups(parsedVolumes, environmentForDeployment(m, m.selectedEnvironment.Id).Volumes)
+				if updateVolumes(m.selectedEnvironment.Id, parsedVolumes) {
+					cmds = append(cmds, showToast(&m, "Volumes saved, they are mounted on the next deployment"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot save volumes, check that the lighthouse is reachable"))
+				}
+				cmds = append(cmds, getEnvironmentsCmd(m.selectedService.Id))
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_APPROVALS && m.approvalForm != nil {
+		form, cmd := m.approvalForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.approvalForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.approvalForm.State == huh.StateAborted {
+			m.approvalForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.approvalForm.State == huh.StateCompleted {
+			m.approvalForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+			if approveIsConfirmed {
+				if approveApprovalId != "" && approveApproval(approveApprovalId, currentUser()) {
+					cmds = append(cmds, showToast(&m, "Request approved"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot approve the request, check that the lighthouse is reachable"))
+				}
+				cmds = append(cmds, menuEnvironmentMsg(m.selectedEnvironment.Id, m.selectedEnvironment.Name))
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_PROMOTE_ENVIRONMENT && m.promoteForm != nil {
+		form, cmd := m.promoteForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.promoteForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.promoteForm.State == huh.StateAborted {
+			m.promoteForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.promoteForm.State == huh.StateCompleted {
+			m.promoteForm.State = huh.StateNormal
+			if promoteIsConfirmed {
+				var target Environment
+				for _, promoteTarget := range m.promoteTargets {
+					if promoteTarget.Id == promoteTargetEnvironmentId {
+						target = promoteTarget
+					}
+				}
+
+				if target.Protected {
+					//The phrase and the approval name the target, ESC returns to the Environment menu of the source
+					m.selectedEnvironment = target
+					startProtectedAction(&m, PROTECTED_ACTION_PROMOTE)
+				} else {
+					cmds = append(cmds, promoteCommit(&m, target))
+				}
+			} else {
+				screenType = SCREEN_TYPE_ENV_MENU
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_COMPARE_ENVIRONMENT && m.compareForm != nil {
+		form, cmd := m.compareForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.compareForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.compareForm.State == huh.StateAborted {
+			m.compareForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_ENV_MENU
+		} else if m.compareForm.State == huh.StateCompleted {
+			m.compareForm.State = huh.StateNormal
+			for _, target := range m.compareTargets {
+				if target.Id == compareEnvironmentId {
+					machines, _ := getMachines().(MachineMsg)
+					lines := diffEnvironments(m.compareSource, target, machines, false)
+					m.compareDiff = formatEnvironmentDiff(m.compareSource, target, lines)
+				}
+			}
+			screenType = SCREEN_TYPE_ENVIRONMENT_DIFF
+		}
+	}
+
 	if screenType == SCREEN_TYPE_ENV_MENU {
 		newList, cmd := m.envMenu.Update(msg)
 		m.envMenu = newList
@@ -1070,6 +2206,73 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	}
 
+	if screenType == SCREEN_TYPE_BACKUPS {
+		m.snapshotList, cmd = m.snapshotList.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_RESTORE_CONFIRMATION {
+		m.restoreConfirmation, cmd = m.restoreConfirmation.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if screenType == SCREEN_TYPE_BACKUP_POLICY && m.backupPolicyForm != nil {
+		form, cmd := m.backupPolicyForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.backupPolicyForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.backupPolicyForm.State == huh.StateAborted {
+			m.backupPolicyForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_BACKUPS
+		} else if m.backupPolicyForm.State == huh.StateCompleted {
+			m.backupPolicyForm.State = huh.StateNormal
+			screenType = SCREEN_TYPE_BACKUPS
+			if backupIsSave {
+				policy := backupPolicyFromForm()
+				if updateBackupPolicy(m.backupResource.resourceType, m.backupResource.resourceId, m.backupResource.volumeName, policy) {
+					m.backupResource.policy = policy
+					cmds = append(cmds, showToast(&m, "Backup schedule of "+m.backupResource.name+" saved"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot save the backup schedule, check that the lighthouse is reachable"))
+				}
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_BACKUP_TARGET && m.backupTargetForm != nil {
+		form, cmd := m.backupTargetForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.backupTargetForm = f
+		}
+
+		cmds = append(cmds, cmd)
+
+		if m.backupTargetForm.State == huh.StateAborted {
+			m.backupTargetForm.State = huh.StateNormal
+			screenType = 1
+		} else if m.backupTargetForm.State == huh.StateCompleted {
+			m.backupTargetForm.State = huh.StateNormal
+			screenType = 1
+			if backupTargetIsSave {
+				if updateBackupTarget(backupTargetFromForm()) {
+					cmds = append(cmds, showToast(&m, "Backup storage saved"))
+				} else {
+					cmds = append(cmds, showToast(&m, "Cannot save the backup storage, check that the lighthouse can reach it"))
+				}
+			}
+		}
+	}
+
+	if screenType == SCREEN_TYPE_DATABASE_MENU {
+		newList, cmd := m.databaseMenu.Update(msg)
+		m.databaseMenu = newList
+		cmds = append(cmds, cmd)
+
+	}
+
 	if screenType == SCREEN_TYPE_ENV_DELETE_CONFIRMATION {
 		m.deleteEnvConfirmation, cmd = m.deleteEnvConfirmation.Update(msg)
 		cmds = append(cmds, cmd)
@@ -1082,6 +2285,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	}
 
+	if screenType == SCREEN_TYPE_DRAIN_CONFIRMATION {
+		m.drainConfirmation, cmd = m.drainConfirmation.Update(msg)
+		cmds = append(cmds, cmd)
+
+	}
+
+	if screenType == SCREEN_TYPE_PROTECTED_ACTION {
+		m.protectedConfirmation, cmd = m.protectedConfirmation.Update(msg)
+		cmds = append(cmds, cmd)
+
+	}
+
 	if screenType == SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION {
 		m.deleteServiceConfirmation, cmd = m.deleteServiceConfirmation.Update(msg)
 		cmds = append(cmds, cmd)
@@ -1092,11 +2307,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) View() string {
+	//Toasts are shown on every screen
+	return m.screenView() + toastView(m) + pendingDeleteView(m)
+}
+
+func (m model) screenView() string {
 	switch screenType {
 	case 1:
 		return appStyle.Render(m.list.View())
 	case 2:
-		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select a machine\nPress ← or ESC to return to main menu")) + listStyle.Render(m.machineList.View()) + "\n\n\n" + listHelpStyle.Render(m.machineList.HelpView()) + "\n"
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(projectBreadcrumb(m)+"Machines")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select a machine, Space to select several, a to select all, x for actions on selected\nPress ← or ESC to return to main menu")) + listStyle.Render(m.machineList.View()) + "\n" + tableFilterView(m.machineFilter, true) + "\n\n" + listHelpStyle.Render(m.machineList.HelpView()) + "\n"
 	case 3:
 		{
 			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Add Machine")) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to main menu")) + listStyle.Render(m.newMachineForm.View()) + "\n"
@@ -1108,13 +2328,65 @@ func (m model) View() string {
 			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Connect a new machine to VPN")) + "\n"
 		}
 	case 5:
-		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select a service\nPress ← or ESC to return to main menu")) + listStyle.Render(m.serviceList.View()) + "\n\n\n" + listHelpStyle.Render(m.serviceList.HelpView()) + "\n"
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(projectBreadcrumb(m)+"Services")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select a service\nPress ← or ESC to return to main menu")) + listStyle.Render(m.serviceList.View()) + "\n" + tableFilterView(m.serviceFilter, false) + "\n\n" + listHelpStyle.Render(m.serviceList.HelpView()) + "\n"
 	case SCREEN_TYPE_ENVIRONMENTS:
-		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name)) + topHintPositionStyle.Render(topHintStyle.Render("Press d to delete the service\nPress Enter to add or select an environment\nPress ← or ESC to return to Services")) + listStyle.Render(m.environmentList.View()) + "\n\n" + listHelpStyle.Render(m.environmentList.HelpView()) + "\n"
+		//Images are pulled from a registry, services deployed from them have no branches, webhook and deploy key
+		serviceHint := "Press d to delete the service, p to set up preview environments, w to show the webhook and deploy key"
+		if isImageService(m.selectedService) {
+			serviceHint = "Press d to delete the service, environments run " + imageReference(m.selectedService.Image)
+		}
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name)) + topHintPositionStyle.Render(topHintStyle.Render(serviceHint+"\nPress Enter to add or select an environment, Space to select several, a to select all, x for actions on selected\nPress ← or ESC to return to Services")) + listStyle.Render(m.environmentList.View()) + "\n" + tableFilterView(m.environmentFilter, true) + "\n" + scheduledDeploymentsView(m) + listHelpStyle.Render(m.environmentList.HelpView()) + "\n"
+
+	case SCREEN_TYPE_SERVICE_KEYS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > Webhook & Deploy Key")) + topHintPositionStyle.Render(topHintStyle.Render("Press w to copy the webhook URL, k to copy the deploy key\nPress ← or ESC to return to Environments")) + listStyle.Render(serviceKeysView(m)) + "\n"
+
+	case SCREEN_TYPE_PREVIEW_SETTINGS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > Preview Environments")) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to Environments")) + baseStyle.Render(m.previewSettingsForm.View()) + "\n"
+
+	case SCREEN_TYPE_BULK_MENU:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(bulkBreadcrumb(m))) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to run the action on all selected "+m.bulkTarget+"\nPress ESC to return")) + listStyle.Render(m.bulkMenu.View()) + "\n"
+
+	case SCREEN_TYPE_BULK_DELETE_CONFIRMATION:
+		if blocked := bulkDeleteBlockedTypes(m); len(blocked) > 0 {
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render(bulkBreadcrumb(m))) + topHintPositionStyle.Render(fmt.Sprintf(
+				"\n%s\n\n %s",
+				bulkResultErrorStyle.Render(" All machines of type '"+strings.Join(blocked, "', '")+"' are selected, deployments can't work without them.\n Add the type to another machine or unselect one of them."),
+				"(esc to quit)"))
+		}
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(bulkBreadcrumb(m))) + topHintPositionStyle.Render(fmt.Sprintf(
+			"\n%s\n Do you really want to delete %s? %s\n\n %s\n\n %s",
+			bulkDeleteImpactView(m),
+			bulkDeleteTitle(m),
+			confirmationHint(bulkDeletePhrase(m)),
+			m.bulkConfirmation.View(),
+			"(esc to quit)"))
+
+	case SCREEN_TYPE_BULK_RUNNING:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(bulkBreadcrumb(m))) + topHintPositionStyle.Render(newMachineHintTitleStyle.Render("\n Running "+m.bulkAction+"...")) + "\n"
+
+	case SCREEN_TYPE_BULK_RESULT:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(bulkBreadcrumb(m))) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter or ESC to return")) + listStyle.Render(m.bulkResult) + "\n"
+
+	case SCREEN_TYPE_PROJECTS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Projects")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to switch to a project or add a new one\nPress r to rename and d to delete the project\nPress ← or ESC to return to main menu")) + listStyle.Render(m.projectList.View()) + "\n\n" + listHelpStyle.Render(m.projectList.HelpView()) + "\n"
+
+	case SCREEN_TYPE_PROJECT_FORM:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Projects")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to Projects")) + baseStyle.Render(m.projectForm.View()) + "\n"
+
+	case SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION:
+		if m.projectDeleteBlocker != "" {
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Projects > "+m.editedProject.Name)) + topHintPositionStyle.Render("\n "+m.projectDeleteBlocker+"\n\n Press ESC to return to Projects")
+		}
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Projects > "+m.editedProject.Name)) + topHintPositionStyle.Render(fmt.Sprintf(
+			"\n Do you really want to delete this project? Type 'y' to confirm or press ESC to cancel.\n\n %s\n\n %s",
+			m.deleteProjectConfirmation.View(),
+			"(esc to quit)"))
 
 	case SCREEN_TYPE_SERVICE_DELETE_CONFIRMATION:
 		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name)) + topHintPositionStyle.Render(fmt.Sprintf(
-			"\n Do you really want to delete this service? Type 'y' to confirm or press ESC to cancel.\n\n %s\n\n %s",
+			"\n%s\n Do you really want to delete this service? %s\n\n %s\n\n %s",
+			serviceDeleteImpactView(m),
+			confirmationHint(serviceDeletePhrase(m)),
 			m.deleteServiceConfirmation.View(),
 			"(esc to quit)"))
 
@@ -1140,13 +2412,9 @@ func (m model) View() string {
 			app.ReleaseTerminal()
 			fmt.Print(m.newMachineJoinHint)
 
-			ok := YesNoPrompt(newMachineHintTitleStyle.Render("\n    Press Enter to return to Main Menu"), true)
-			if ok {
-				screenType = 1
-				app.RestoreTerminal()
-			} else {
-				app.RestoreTerminal()
-			}
+			ActionPrompt(newMachineHintTitleStyle.Render(actionPromptLabel(m.newMachineJoinActions, "return to Main Menu")), m.newMachineJoinActions)
+			screenType = 1
+			app.RestoreTerminal()
 
 		}
 	case 11:
@@ -1156,30 +2424,91 @@ func (m model) View() string {
 			app.ReleaseTerminal()
 			fmt.Print(m.newEnvironmentHint)
 
-			ok := YesNoPrompt(newMachineHintTitleStyle.Render("\n    Press Enter to return to Main Menu"), true)
-			if ok {
-				screenType = 1
-				app.RestoreTerminal()
-			} else {
-				app.RestoreTerminal()
-			}
+			ActionPrompt(newMachineHintTitleStyle.Render(actionPromptLabel(m.newEnvironmentActions, "return to Main Menu")), m.newEnvironmentActions)
+			screenType = 1
+			app.RestoreTerminal()
 
 		}
 	case SCREEN_TYPE_ENV_MENU:
 		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select\nPress ← or ESC to return to Environments")) + listStyle.Render(m.envMenu.View()) + "\n"
 
+	case SCREEN_TYPE_PROTECTED_ACTION:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+m.protectedAction)) + topHintPositionStyle.Render(protectedActionView(m))
+
+	case SCREEN_TYPE_SCHEDULE_DEPLOY:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+MENU_SCHEDULE_DEPLOY)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.scheduleDeployForm.View()) + "\n"
+
+	case SCREEN_TYPE_CANCEL_SCHEDULED_DEPLOY:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+MENU_CANCEL_SCHEDULED_DEPLOY)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.cancelScheduledDeployForm.View()) + "\n"
+
+	case SCREEN_TYPE_FREEZE_WINDOWS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+MENU_FREEZE_WINDOWS)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.freezeWindowsForm.View()) + "\n"
+
+	case SCREEN_TYPE_VOLUMES:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+MENU_VOLUMES)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.volumesForm.View()) + "\n"
+
+	case SCREEN_TYPE_APPROVALS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name+" > "+MENU_APPROVALS)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.approvalForm.View()) + "\n"
+
+	case SCREEN_TYPE_EDIT_MACHINE:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.selectedMachine.Name+" > Edit")) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to the Machine menu")) + baseStyle.Render(m.editMachineForm.View()) + "\n"
+
+	case SCREEN_TYPE_BACKUPS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(backupsBreadcrumb(m))) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to restore a snapshot, b to back up now, e to edit the schedule\nPress ← or ESC to return")) + listStyle.Render(backupsView(m)+"\n"+m.snapshotList.View()) + "\n"
+
+	case SCREEN_TYPE_BACKUP_POLICY:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(backupsBreadcrumb(m)+" > Schedule")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to Backups")) + baseStyle.Render(m.backupPolicyForm.View()) + "\n"
+
+	case SCREEN_TYPE_RESTORE_CONFIRMATION:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(backupsBreadcrumb(m)+" > Restore")) + topHintPositionStyle.Render(restoreConfirmationView(m))
+
+	case SCREEN_TYPE_BACKUP_TARGET:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(MENU_BACKUP_STORAGE)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to main menu")) + baseStyle.Render(m.backupTargetForm.View()) + "\n"
+
+	case SCREEN_TYPE_NEW_DATABASE:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render(MENU_ADD_DATABASE)) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to main menu")) + baseStyle.Render(m.newDatabaseForm.View()) + "\n"
+
+	case SCREEN_TYPE_DATABASE_MENU:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select\nPress ← or ESC to return to Services")) + listStyle.Render(m.databaseMenu.View()) + "\n"
+
+	case SCREEN_TYPE_LINK_ENVIRONMENTS:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+MENU_LINK_ENVIRONMENTS)) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to the Database menu")) + baseStyle.Render(m.linkEnvironmentsForm.View()) + "\n"
+
 	case SCREEN_TYPE_MACHINE_MENU:
 		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.selectedMachine.Name)) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select\nPress ← or ESC to return to Machines")) + listStyle.Render(m.machineMenu.View()) + "\n"
 
 	case SCREEN_TYPE_ENV_DELETE_CONFIRMATION:
 		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name)) + topHintPositionStyle.Render(fmt.Sprintf(
-			"\n Do you really want to delete this environment? Type 'y' to confirm or press ESC to cancel.\n\n %s\n\n %s",
+			"\n%s\n Do you really want to delete this environment? %s\n\n %s\n\n %s",
+			environmentVolumesView(environmentForDeployment(m, m.selectedEnvironment.Id)),
+			confirmationHint(environmentDeletePhrase(m)),
 			m.deleteEnvConfirmation.View(),
 			"(esc to quit)"))
 
+	case SCREEN_TYPE_DRAIN_CONFIRMATION:
+		if m.drainMachine.err != nil {
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.drainMachine.machine.Name+" > Drain")) + topHintPositionStyle.Render(fmt.Sprintf(
+				"\n%s\n %s",
+				bulkResultErrorStyle.Render(" The machine can't be drained, "+m.drainMachine.err.Error()+" to check environments that lose it. Try again later."),
+				"(esc to quit)"))
+		}
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.drainMachine.machine.Name+" > Drain")) + topHintPositionStyle.Render(fmt.Sprintf(
+			"\n Draining removes this machine from load balancing and deployments until it's undrained.\n\n%s\n Do you really want to drain this machine? Type 'y' to confirm or press ESC to cancel.\n\n %s\n\n %s",
+			machineEnvironmentsView(m.drainMachine.machine.Id, m.drainMachine.environments, m.machines),
+			m.drainConfirmation.View(),
+			"(esc to quit)"))
+
 	case SCREEN_TYPE_MACHINE_DELETE_CONFIRMATION:
+		if isMachineDeleteBlocked(m.machineDeleteImpact) {
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.selectedMachine.Name)) + topHintPositionStyle.Render(fmt.Sprintf(
+				"\n%s\n %s",
+				machineDeleteImpactView(m.machineDeleteImpact),
+				"(esc to quit)"))
+		}
 		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Machines > "+m.selectedMachine.Name)) + topHintPositionStyle.Render(fmt.Sprintf(
-			"\n Do you really want to delete this machine? Type 'y' to confirm or press ESC to cancel.\n\n %s\n\n %s",
+			"\n%s\n Do you really want to delete this machine? %s\n\n %s\n\n %s",
+			machineDeleteImpactView(m.machineDeleteImpact),
+			confirmationHint(machineDeletePhrase(m)),
 			m.deleteMachineConfirmation.View(),
 			"(esc to quit)"))
 
@@ -1187,9 +2516,29 @@ func (m model) View() string {
 		{
 			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Edit Environment")) + topHintPositionStyle.Render(topHintStyle.Render("Press X or Space to select options\nPress Enter to confirm\nPress ESC to return to main menu")) + baseStyle.Render(m.newEnvironmentForm.View()) + "\n"
 		}
+	case SCREEN_TYPE_PROMOTE_ENVIRONMENT:
+		{
+			if m.promoteForm == nil {
+				return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.promoteSource.Name+" > Promote")) + topHintPositionStyle.Render(newMachineHintTitleStyle.Render(m.promoteHint)) + "\n"
+			}
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.promoteSource.Name+" > Promote")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to confirm\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.promoteForm.View()) + "\n"
+		}
+	case SCREEN_TYPE_COMPARE_ENVIRONMENT:
+		{
+			if m.compareForm == nil {
+				return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.compareSource.Name+" > Compare")) + topHintPositionStyle.Render(m.compareDiff) + "\n"
+			}
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.compareSource.Name+" > Compare")) + topHintPositionStyle.Render(topHintStyle.Render("Press Enter to select\nPress ESC to return to the Environment menu")) + baseStyle.Render(m.compareForm.View()) + "\n"
+		}
+	case SCREEN_TYPE_ENVIRONMENT_DIFF:
+		return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.compareSource.Name+" > Compare")) + topHintPositionStyle.Render(topHintStyle.Render("Changed settings are marked with *, values of environment variables are hidden\nPress Enter or ESC to return to the Environment menu")) + listStyle.Render(m.compareDiff) + "\n"
+
 	case SCREEN_TYPE_DEPLOYMENT_SCHEDULED:
 		{
-			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name)) + topHintPositionStyle.Render(newMachineHintTitleStyle.Render("\n Deployment is scheduled. \n Press Enter to dismiss this message")) + "\n"
+			if m.deploymentHint != "" {
+				return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name)) + topHintPositionStyle.Render(newMachineHintTitleStyle.Render("\n "+m.deploymentHint+" \n Press Enter to dismiss this message")) + "\n"
+			}
+			return breadhumbPositionStyle.Render(breadhumbStyle.Render("Services > "+m.selectedService.Name+" > "+m.selectedEnvironment.Name)) + topHintPositionStyle.Render(newMachineHintTitleStyle.Render("\n Deployment is scheduled. \n Press Enter to dismiss this message, you will be notified when the deployment finishes")+"\n\n "+deploymentProgressView(m, m.selectedEnvironment.Id)+"\n\n"+machineProgressView(m)) + "\n"
 		}
 	}
 	return ""
@@ -1218,15 +2567,25 @@ func ClearTerminal() {
 
 func main() {
 
-	ClearTerminal()
-
 	lighthouseIP := flag.String("i", "", "a string")
 	flag.Parse()
 
 	if *lighthouseIP != "" {
-		executeScriptString("lsof -i tcp:5445 | awk 'NR!=1 {print $2}' | xargs kill\nssh -o ExitOnForwardFailure=yes -f -N -L 5445:localhost:5445 root@" + *lighthouseIP)
+		//Output of commands like export is redirected to files, the tunnel output goes to stderr
+		output := io.Writer(os.Stdout)
+		if flag.NArg() > 0 {
+			output = os.Stderr
+		}
+		executeScriptStringTo("lsof -i tcp:5445 | awk 'NR!=1 {print $2}' | xargs kill\nssh -o ExitOnForwardFailure=yes -f -N -L 5445:localhost:5445 root@"+*lighthouseIP, output)
 	}
 
+	//Commands like export and import don't start the TUI
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
+	ClearTerminal()
+
 	app = tea.NewProgram(newModel() /*, tea.WithAltScreen()*/)
 
 	if _, err := app.Run(); err != nil {
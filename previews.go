@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const PREVIEW_ENVIRONMENTS_STRING = "Previews"
+const PREVIEW_BRANCH_PLACEHOLDER = "{branch}"
+
+var (
+	previewEnabled        bool
+	previewBranchPattern  string
+	previewDomainTemplate string
+	previewPort           string
+	previewMachines       []string
+	previewIsSave         bool
+)
+
+type PreviewSettingsMsg struct {
+	service Service
+	err     error //the form isn't shown, saving it would overwrite the service with empty fields
+}
+
+func previewSettingsMsg(serviceId string) tea.Cmd {
+	return func() tea.Msg {
+		var msg PreviewSettingsMsg
+		services, ok := getServices().(ServicesMsg)
+		if !ok {
+			msg.err = errors.New("services can't be loaded")
+			return msg
+		}
+
+		for _, service := range services {
+			if service.Id == serviceId {
+				msg.service = service
+			}
+		}
+
+		if msg.service.Id == "" {
+			msg.err = errors.New("the service isn't found on the lighthouse")
+		}
+		return msg
+	}
+}
+
+func createPreviewSettingsForm(m *model, service Service, machineOptions []huh.Option[string], machines []Machine) {
+
+	previewEnabled = service.PreviewEnabled
+	previewBranchPattern = service.PreviewBranchPattern
+	previewDomainTemplate = service.PreviewDomainTemplate
+	previewPort = service.PreviewPort
+	previewIsSave = true
+
+	previewMachines = previewMachines[:0]
+	for _, machine := range machines {
+		if slices.Contains(service.PreviewMachineIds, machine.Id) {
+			previewMachines = append(previewMachines, machine.Id)
+		}
+	}
+
+	m.previewSettingsForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Preview Environments").
+				Description("Create an environment for each pushed branch that matches the pattern and remove it when the branch is deleted").
+				Affirmative("Enabled").
+				Negative("Disabled").
+				Value(&previewEnabled),
+			huh.NewInput().
+				Title("Branch Pattern").
+				Placeholder("feature/*, pr-*, etc").
+				Value(&previewBranchPattern).
+				Validate(func(str string) error {
+					if !previewEnabled {
+						return nil
+					}
+					if str == "" {
+						return errors.New("branch pattern is required")
+					}
+					if _, err := path.Match(str, ""); err != nil {
+						return errors.New("invalid branch pattern")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Domain Template").
+				Description("The DNS record should be a wildcard, for example *.preview.example.com, that resolves to the IP address of the load balancer machine").
+				Placeholder(PREVIEW_BRANCH_PLACEHOLDER+".preview.example.com").
+				Value(&previewDomainTemplate).
+				Validate(func(str string) error {
+					if previewEnabled && !strings.Contains(str, PREVIEW_BRANCH_PLACEHOLDER) {
+						return errors.New("domain template should contain " + PREVIEW_BRANCH_PLACEHOLDER)
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Port").
+				Placeholder("4008, 5005, etc").
+				Value(&previewPort),
+			huh.NewMultiSelect[string]().
+				Title("Choose Servers to Deploy Previews").
+				Value(&previewMachines).
+				Options(machineOptions...),
+			huh.NewConfirm().
+				Key("done").
+				Title("Save preview settings?").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&previewIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.previewSettingsForm.Init()
+}
+
+// savePreviewSettings applies the preview settings form to the service and saves it
+func savePreviewSettings(service Service) Service {
+	service.PreviewEnabled = previewEnabled
+	service.PreviewBranchPattern = previewBranchPattern
+	service.PreviewDomainTemplate = previewDomainTemplate
+	service.PreviewPort = previewPort
+
+	machines := getMachines().(MachineMsg) //type asssertion
+	service.PreviewMachineIds = []string{}
+	for _, machine := range machines {
+		if slices.Contains(previewMachines, machine.Id) {
+			service.PreviewMachineIds = append(service.PreviewMachineIds, machine.Id)
+		}
+	}
+
+	return updateService(service)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const MENU_PROJECT_PREFIX = "Project: "
+const ALL_PROJECTS_STRING = "All projects"
+const ADD_PROJECT_STRING = "Add Project"
+
+var (
+	newProjectName  string
+	newProjectIsAdd bool
+)
+
+// projectMenuItem is the first item of the main menu, it shows the current project
+func projectMenuItem(project Project) item {
+	name := ALL_PROJECTS_STRING
+	if project.Id != "" {
+		name = project.Name
+	}
+	return item{title: MENU_PROJECT_PREFIX + name, description: "Switch between projects"}
+}
+
+// projectBreadcrumb returns the breadcrumb prefix for project-scoped screens
+func projectBreadcrumb(m model) string {
+	if m.selectedProject.Id == "" {
+		return ""
+	}
+	return m.selectedProject.Name + " > "
+}
+
+func selectProject(m *model, project Project) {
+	m.selectedProject = project
+	//Machines of the previous project aren't hidden while machines of this one are loaded
+	m.projectMachineIds = nil
+	m.usedMachineIds = nil
+	m.list.SetItem(0, projectMenuItem(project))
+}
+
+func createProjectsTable(m *model, projects ProjectsMsg) {
+	columns := []table.Column{
+		{Title: "ID", Width: 15},
+		{Title: "Name", Width: 30},
+	}
+
+	rows := []table.Row{
+		{ADD_PROJECT_STRING, ""},
+		{ALL_PROJECTS_STRING, ""},
+	}
+
+	indexToSelect := 1
+	for index, project := range projects {
+		if project.Id == m.selectedProject.Id {
+			indexToSelect = index + 2
+		}
+		rows = append(rows, table.Row{project.Id, project.Name})
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(20),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.HiddenBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("255")).
+		Background(lipgloss.Color("#bfbfbf")).
+		Bold(true)
+	s.Cell = s.Cell.Height(1)
+	t.SetStyles(s)
+
+	m.projectList = t
+	v, _ := listStyle.GetFrameSize()
+	m.projectList.SetWidth(m.screenWidth - 2*v)
+	m.projectList.SetHeight(m.screenHeight - listTopHintHeght)
+
+	m.projectList.MoveDown(indexToSelect)
+}
+
+// isProjectRow reports whether the row is a project and not one of the service rows
+func isProjectRow(row table.Row) bool {
+	return row != nil && row[0] != ADD_PROJECT_STRING && row[0] != ALL_PROJECTS_STRING
+}
+
+func createProjectDetails(m *model, confirmationTitle string, confirmationBtn string) {
+
+	m.projectForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Project Name").
+				Value(&newProjectName).
+				Validate(func(str string) error {
+					if strings.TrimSpace(str) == "" {
+						return errors.New("project name is required")
+					}
+					return nil
+				}),
+			huh.NewConfirm().
+				Key("done").
+				Title(confirmationTitle).
+				Affirmative(confirmationBtn).
+				Negative("Cancel").
+				Value(&newProjectIsAdd),
+		),
+	)
+
+	m.projectForm.Init()
+}
+
+// projectDeleteBlocker returns why the project can't be deleted, services of a deleted project
+// would point to a missing project and be listed only under All projects
+func projectDeleteBlocker(projectId string) string {
+	services, ok := getServices().(ServicesMsg)
+	if !ok {
+		return "Services can't be loaded, the project can't be deleted until they are checked."
+	}
+
+	names := []string{}
+	for _, service := range services {
+		if service.ProjectId == projectId {
+			names = append(names, service.Name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return "This project has services: " + strings.Join(names, ", ") + ". Delete them before deleting the project."
+}
+
+// getProjectOptions returns options for the project select of the Add Service form
+func getProjectOptions() []huh.Option[string] {
+	projects, ok := getProjects().(ProjectsMsg)
+	if !ok || len(projects) == 0 {
+		return nil
+	}
+
+	opts := []huh.Option[string]{huh.NewOption("No project", "")}
+	for _, project := range projects {
+		opts = append(opts, huh.NewOption(project.Name, project.Id))
+	}
+	return opts
+}
+
+// ProjectMachinesMsg lists machines used by environments, machines used only by other projects are hidden
+type ProjectMachinesMsg struct {
+	projectId         string
+	projectMachineIds []string
+	usedMachineIds    []string
+}
+
+func projectMachinesMsg(projectId string) tea.Cmd {
+	return func() tea.Msg {
+		msg := ProjectMachinesMsg{projectId: projectId}
+		msg.projectMachineIds, msg.usedMachineIds = getProjectMachineIds(projectId)
+		return msg
+	}
+}
+
+// getProjectMachineIds returns IDs of machines used by environments of the project
+// and IDs of machines used by any environment
+func getProjectMachineIds(projectId string) ([]string, []string) {
+	projectMachineIds := []string{}
+	usedMachineIds := []string{}
+
+	services, ok := getServices().(ServicesMsg)
+	if !ok {
+		return projectMachineIds, usedMachineIds
+	}
+
+	for _, service := range services {
+		environments, ok := getEnvironments(service.Id).(EnvironmentsMsg)
+		if !ok {
+			continue
+		}
+		for _, environment := range environments {
+			usedMachineIds = append(usedMachineIds, environment.MachineIds...)
+			if service.ProjectId == projectId {
+				projectMachineIds = append(projectMachineIds, environment.MachineIds...)
+			}
+		}
+	}
+
+	return projectMachineIds, usedMachineIds
+}
+
+// isMachineInProject reports whether the machine should be listed for the selected project.
+// Machines that aren't used by any environment are shared, so they are listed in every project.
+func isMachineInProject(m model, machine Machine) bool {
+	if m.selectedProject.Id == "" {
+		return true
+	}
+	return slices.Contains(m.projectMachineIds, machine.Id) || !slices.Contains(m.usedMachineIds, machine.Id)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Environments of the project are loaded by a command, the Machines table isn't blocked by them
+func TestProjectMachinesAreLoadedInBackground(t *testing.T) {
+	serviceRequests := 0
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/service":
+			serviceRequests++
+			json.NewEncoder(w).Encode([]Service{{Id: "s1", Name: "web", ProjectId: "p1"}, {Id: "s2", Name: "api", ProjectId: "p2"}})
+		case "/service/s1/environment":
+			json.NewEncoder(w).Encode([]Environment{{Id: "e1", MachineIds: []string{"m1"}}})
+		case "/service/s2/environment":
+			json.NewEncoder(w).Encode([]Environment{{Id: "e2", MachineIds: []string{"m2"}}})
+		default:
+			w.Write([]byte("[]"))
+		}
+	}))
+	previousScreenType := screenType
+	t.Cleanup(func() { screenType = previousScreenType })
+
+	m := newModel()
+	m.screenWidth, m.screenHeight = 120, 40
+	selectProject(&m, Project{Id: "p1", Name: "shop"})
+	screenType = SCREEN_TYPE_PROJECTS
+	updated, _ := m.Update(MachineMsg{{Id: "m1", Name: "web-1"}, {Id: "m2", Name: "api-1"}, {Id: "m3", Name: "spare"}})
+	m = updated.(model)
+	if serviceRequests != 0 {
+		t.Fatalf("services loaded %d times while handling machines", serviceRequests)
+	}
+
+	updated, _ = m.Update(projectMachinesMsg("p1")())
+	m = updated.(model)
+	rows := m.machineList.Rows()
+	if len(rows) != 2 || rows[0][0] != "m1" || rows[1][0] != "m3" {
+		t.Errorf("machines of project p1 = %v, want m1 and the unused m3", rows)
+	}
+}
+
+// A project that isn't deleted stays selected
+func TestDeleteProjectFailure(t *testing.T) {
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			http.Error(w, "project is used", http.StatusConflict)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	previousScreenType := screenType
+	t.Cleanup(func() { screenType = previousScreenType })
+
+	m := newModel()
+	selectProject(&m, Project{Id: "p1", Name: "shop"})
+	m.editedProject = m.selectedProject
+	m.deleteProjectConfirmation.SetValue("y")
+	screenType = SCREEN_TYPE_PROJECT_DELETE_CONFIRMATION
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.selectedProject.Id != "p1" {
+		t.Errorf("selected project = %q after a failed delete, want p1", m.selectedProject.Id)
+	}
+	if m.toast == "" {
+		t.Error("failed delete shows no error")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const MENU_APPROVALS = "Approve Requests"
+
+const APPROVAL_POLL_INTERVAL = 2 * time.Second
+
+// PROTECTED_MARK is shown next to protected environments in the Environments table
+const PROTECTED_MARK = "🔒"
+
+// PROTECTED_ACTION_PROMOTE deploys the promoted commit to a protected target environment
+const PROTECTED_ACTION_PROMOTE = "promote"
+
+// protectedActions are Environment menu actions and deployment controls that require
+// a confirmation phrase or an approval by another user on protected environments
+var protectedActions = map[string]string{
+	MENU_DEPLOY:              "deploy",
+	MENU_SCHEDULE_DEPLOY:     "deploy",
+	MENU_EDIT:                "edit",
+	MENU_FREEZE_WINDOWS:      "edit",
+	MENU_VOLUMES:             "edit",
+	MENU_DELETE:              "delete",
+	DEPLOYMENT_ACTION_PAUSE:  "pause",
+	DEPLOYMENT_ACTION_ABORT:  "abort",
+	PROTECTED_ACTION_PROMOTE: "deploy",
+}
+
+var (
+	approveApprovalId  string
+	approveIsConfirmed bool
+)
+
+type ApprovalMsg struct {
+	id       string
+	approval Approval
+	err      error
+}
+
+// currentUser identifies who requests and approves actions, TURBOCLOUD_USER overrides
+// the local user name for people sharing the same machine.
+// The lighthouse doesn't authenticate users, so anyone can claim any name: approvals are advisory,
+// they guard against mistakes, not against someone with access to the lighthouse
+func currentUser() string {
+	if name := os.Getenv("TURBOCLOUD_USER"); name != "" {
+		return name
+	}
+
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return name
+	}
+	return name + "@" + hostname
+}
+
+func protectedMark(environment Environment) string {
+	if environment.Protected {
+		return PROTECTED_MARK
+	}
+	return ""
+}
+
+// needsProtectedConfirmation reports whether the Environment menu action should be confirmed first
+func needsProtectedConfirmation(m model, action string) bool {
+	_, ok := protectedActions[action]
+	return ok && environmentForDeployment(m, m.selectedEnvironment.Id).Protected
+}
+
+func protectedActionPhrase(m model) string {
+	return protectedActions[m.protectedAction] + " " + m.selectedEnvironment.Name
+}
+
+// startProtectedAction asks for the confirmation phrase or an approval before the action runs
+func startProtectedAction(m *model, action string) {
+	screenType = SCREEN_TYPE_PROTECTED_ACTION
+	m.protectedAction = action
+	m.approval = Approval{}
+	m.approvalHint = ""
+	m.protectedConfirmation.SetValue("")
+	m.protectedConfirmation.Focus()
+}
+
+// requestApproval records the request on the lighthouse and waits until another user approves it
+func requestApproval(m *model) tea.Cmd {
+	if m.approval.Id != "" {
+		return nil
+	}
+
+	approval, err := postApproval(m.selectedEnvironment.Id, protectedActions[m.protectedAction], currentUser())
+	if err != nil {
+		m.approvalHint = "Cannot request an approval: " + err.Error()
+		return nil
+	}
+
+	m.approval = approval
+	m.approvalHint = ""
+	return pollApproval(approval.Id)
+}
+
+func pollApproval(approvalId string) tea.Cmd {
+	return tea.Tick(APPROVAL_POLL_INTERVAL, func(t time.Time) tea.Msg {
+		approval, err := getApproval(approvalId)
+		return ApprovalMsg{id: approvalId, approval: approval, err: err}
+	})
+}
+
+// isApproved reports whether the request has been approved by someone other than the requester
+func isApproved(approval Approval) bool {
+	return approval.Status == APPROVAL_STATUS_APPROVED && approval.ApprovedBy != "" && approval.ApprovedBy != approval.RequestedBy
+}
+
+// cancelApproval removes the request that is still waiting for an approval
+func cancelApproval(m *model) {
+	if m.approval.Id != "" {
+		deleteApproval(m.approval.Id)
+		m.approval = Approval{}
+	}
+	m.protectedConfirmation.Blur()
+}
+
+// runProtectedAction runs the action after the phrase is typed or the request is approved,
+// an approval can't be used again
+func runProtectedAction(m model) (tea.Model, tea.Cmd) {
+	cancelApproval(&m)
+	screenType = SCREEN_TYPE_ENV_MENU
+
+	if isDeploymentAction(m.protectedAction) {
+		return m, runDeploymentAction(&m, m.protectedAction)
+	}
+
+	if m.protectedAction == PROTECTED_ACTION_PROMOTE {
+		return m, promoteCommit(&m, m.selectedEnvironment)
+	}
+
+	if m.protectedAction == MENU_DELETE {
+		//The phrase or the approval replaces the delete confirmation, the deletion still can be undone
+		return m, tea.Batch(scheduleDelete(&m, DELETE_TARGET_ENVIRONMENT, m.selectedEnvironment.Id, m.selectedEnvironment.Name, m.selectedService.Id), getEnvironmentsCmd(m.selectedService.Id))
+	}
+
+	return m, runEnvironmentMenuAction(&m, m.protectedAction)
+}
+
+// isDeploymentAction reports whether the protected action controls a running deployment
+func isDeploymentAction(action string) bool {
+	return action == DEPLOYMENT_ACTION_PAUSE || action == DEPLOYMENT_ACTION_ABORT
+}
+
+// pendingApprovals returns requests of other users that can be approved
+func pendingApprovals(environmentId string) []Approval {
+	approvals := []Approval{}
+	requester := currentUser()
+	for _, approval := range getApprovals(environmentId) {
+		if approval.Status == APPROVAL_STATUS_PENDING && approval.RequestedBy != requester {
+			approvals = append(approvals, approval)
+		}
+	}
+	return approvals
+}
+
+// createApprovalForm asks which request of another user should be approved
+func createApprovalForm(m *model) {
+
+	approvalOptions := []huh.Option[string]{}
+	for _, approval := range m.pendingApprovals {
+		approvalOptions = append(approvalOptions, huh.NewOption(approval.Action+" "+m.selectedEnvironment.Name+" requested by "+approval.RequestedBy, approval.Id))
+	}
+
+	approveApprovalId = ""
+	approveIsConfirmed = true
+
+	m.approvalForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Approve Request").
+				Description(m.selectedEnvironment.Name+" is protected, an approval allows a single action").
+				Options(approvalOptions...).
+				Value(&approveApprovalId),
+			huh.NewConfirm().
+				Key("done").
+				Title("Approve this request?").
+				Affirmative("Approve").
+				Negative("Cancel").
+				Value(&approveIsConfirmed),
+		),
+	)
+
+	m.approvalForm.Init()
+}
+
+func protectedActionView(m model) string {
+	hint := fmt.Sprintf(
+		"\n %s is protected, %s requires a confirmation or an approval by another user.\n\n Type '%s' to continue:\n\n %s\n\n",
+		m.selectedEnvironment.Name, protectedActions[m.protectedAction], protectedActionPhrase(m), m.protectedConfirmation.View())
+
+	if volumesView := environmentVolumesView(environmentForDeployment(m, m.selectedEnvironment.Id)); m.protectedAction == MENU_DELETE && volumesView != "" {
+		hint = "\n" + volumesView + hint
+	}
+
+	if m.approval.Id != "" {
+		hint += " Waiting for another user to approve it in Services > " + m.selectedService.Name + " > " + m.selectedEnvironment.Name + " > " + MENU_APPROVALS + "\n"
+	} else {
+		hint += " Or press ctrl+r to request an approval by another user.\n Approvals are advisory, the lighthouse doesn't verify who approves them.\n"
+	}
+	if m.approvalHint != "" {
+		hint += "\n " + bulkResultErrorStyle.Render(m.approvalHint) + "\n"
+	}
+
+	return hint + "\n (esc to quit)"
+}
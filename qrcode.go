@@ -0,0 +1,124 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Scanners need at least 4 light modules around the code, see ISO/IEC 18004
+const QR_QUIET_ZONE = 4
+const QR_PNG_MODULE_SIZE = 8
+
+// Modules are rendered as black on white even in terminals with a dark theme, otherwise phones can't scan them
+var qrStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#FFFFFF"))
+
+// encodeQR returns the modules of the text's QR code at error correction level M, surrounded by the quiet zone
+func encodeQR(text string) ([][]bool, error) {
+	qr, err := qrcode.New(text, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	qr.DisableBorder = true
+	symbol := qr.Bitmap()
+
+	size := len(symbol) + 2*QR_QUIET_ZONE
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+	for y, row := range symbol {
+		copy(modules[y+QR_QUIET_ZONE][QR_QUIET_ZONE:], row)
+	}
+	return modules, nil
+}
+
+// qrTerminalView draws two rows of modules per line with half blocks, so the code keeps its square shape
+func qrTerminalView(modules [][]bool) string {
+	isDark := func(x int, y int) bool {
+		return y < len(modules) && modules[y][x]
+	}
+
+	lines := []string{}
+	for y := 0; y < len(modules); y += 2 {
+		var line strings.Builder
+		for x := range modules[y] {
+			top, bottom := isDark(x, y), isDark(x, y+1)
+			switch {
+			case top && bottom:
+				line.WriteString("█")
+			case top:
+				line.WriteString("▀")
+			case bottom:
+				line.WriteString("▄")
+			default:
+				line.WriteString(" ")
+			}
+		}
+		lines = append(lines, "    "+qrStyle.Render(line.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeQRPNG saves the QR code as a black and white PNG image, an existing file is never overwritten
+func writeQRPNG(modules [][]bool, fileName string) error {
+	size := len(modules) * QR_PNG_MODULE_SIZE
+	img := image.NewGray(image.Rect(0, 0, size, size))
+
+	for py := range size {
+		for px := range size {
+			if modules[py/QR_PNG_MODULE_SIZE][px/QR_PNG_MODULE_SIZE] {
+				img.SetGray(px, py, color.Gray{Y: 0})
+			} else {
+				img.SetGray(px, py, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, img)
+}
+
+// qrFilePath returns where the QR code of the machine is saved, the home directory is used
+// instead of the current one because the CLI is often started from a project repository
+func qrFilePath(machine Machine) string {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "turbocloud-join-"+machine.Id+".png")
+}
+
+// joinQRHint renders the join command as a QR code for machines provisioned from a phone
+// and adds an action to save it as a PNG file, the full path is shown before the key is pressed
+func joinQRHint(m *model, machine Machine, joinCommand string) string {
+	modules, err := encodeQR(joinCommand)
+	if err != nil {
+		return ""
+	}
+
+	fileName := qrFilePath(machine)
+	m.newMachineJoinActions = append(m.newMachineJoinActions, promptAction{
+		key:         "p",
+		description: "save the QR code to " + fileName,
+		run: func() string {
+			if err := writeQRPNG(modules, fileName); err != nil {
+				return "Cannot save the QR code: " + err.Error()
+			}
+			return "QR code saved to " + fileName
+		},
+	})
+
+	return "\n\n    • Or scan the QR code with the device you use to access the machine:\n\n" + qrTerminalView(modules)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testJoinCommand = "curl https://turbocloud.dev/setup | bash -s -- -j https://lighthouse.example.com/join/V1StGXR8_Z5jdHi6B-myT"
+
+// Finder patterns, timing patterns and the quiet zone must be where scanners look for them
+func TestEncodeQRLayout(t *testing.T) {
+	modules, err := encodeQR(testJoinCommand)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := len(modules) - 2*QR_QUIET_ZONE
+	if size < 21 || (size-17)%4 != 0 {
+		t.Fatalf("symbol size %d isn't a QR version size", size)
+	}
+	isDark := func(x int, y int) bool {
+		return modules[QR_QUIET_ZONE+y][QR_QUIET_ZONE+x]
+	}
+
+	for y, row := range modules {
+		if len(row) != len(modules) {
+			t.Fatalf("row %d has %d modules, want %d", y, len(row), len(modules))
+		}
+		for x, dark := range row {
+			inQuietZone := x < QR_QUIET_ZONE || y < QR_QUIET_ZONE || x >= len(modules)-QR_QUIET_ZONE || y >= len(modules)-QR_QUIET_ZONE
+			if inQuietZone && dark {
+				t.Fatalf("module %d,%d of the quiet zone is dark", x, y)
+			}
+		}
+	}
+
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := range 7 {
+			for dx := range 7 {
+				ring := max(dx-3, 3-dx, dy-3, 3-dy)
+				if want := ring != 2; isDark(corner[0]+dx, corner[1]+dy) != want {
+					t.Fatalf("finder pattern at %v: module %d,%d dark = %v, want %v", corner, dx, dy, !want, want)
+				}
+			}
+		}
+	}
+
+	for i := 8; i < size-8; i++ {
+		if want := i%2 == 0; isDark(i, 6) != want || isDark(6, i) != want {
+			t.Fatalf("timing pattern module %d isn't %v", i, want)
+		}
+	}
+}
+
+// The format information next to the top left finder pattern must be a valid code word of level M
+func TestEncodeQRFormatInformation(t *testing.T) {
+	for _, text := range []string{"a", "turbocloud", testJoinCommand, strings.Repeat("0123456789", 20)} {
+		modules, err := encodeQR(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isDark := func(x int, y int) bool {
+			return modules[QR_QUIET_ZONE+y][QR_QUIET_ZONE+x]
+		}
+
+		// Positions of format bits 0-14 around the top left finder pattern, x then y
+		positions := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+		format := 0
+		for i, position := range positions {
+			if isDark(position[0], position[1]) {
+				format |= 1 << i
+			}
+		}
+
+		mask := (format>>10 ^ 0b101) & 7
+		if want := qrFormatBits(0b00, mask); format != want {
+			t.Fatalf("%q: format information %015b, want %015b of level M with mask %d", text, format, want, mask)
+		}
+	}
+}
+
+// qrFormatBits computes the BCH(15,5) format information of the level and mask from ISO/IEC 18004
+func qrFormatBits(level int, mask int) int {
+	data := level<<3 | mask
+	remainder := data
+	for range 10 {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+func TestEncodeQRTooLong(t *testing.T) {
+	if _, err := encodeQR(strings.Repeat("x", 3000)); err == nil {
+		t.Fatal("expected an error for text that doesn't fit any version")
+	}
+}
+
+func TestWriteQRPNG(t *testing.T) {
+	modules, err := encodeQR(testJoinCommand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(t.TempDir(), "join.png")
+
+	if err := writeQRPNG(modules, fileName); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(modules) * QR_PNG_MODULE_SIZE; img.Bounds().Dx() != want || img.Bounds().Dy() != want {
+		t.Fatalf("image is %v, want %dx%d", img.Bounds(), want, want)
+	}
+
+	if err := writeQRPNG(modules, fileName); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected an existing file to be kept, got %v", err)
+	}
+}
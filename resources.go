@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const ENVIRONMENT_DEFAULT_REPLICAS = 1
+
+var (
+	newEnvironmentReplicas    string
+	newEnvironmentCPULimit    string
+	newEnvironmentMemoryLimit string
+)
+
+// setResourcesForm fills replicas and resource limits of the environment form, empty limits are unlimited
+func setResourcesForm(environment Environment) {
+	newEnvironmentReplicas = strconv.Itoa(max(environment.Replicas, ENVIRONMENT_DEFAULT_REPLICAS))
+	newEnvironmentCPULimit = ""
+	if environment.CPULimit > 0 {
+		newEnvironmentCPULimit = strconv.FormatFloat(environment.CPULimit, 'f', -1, 64)
+	}
+	newEnvironmentMemoryLimit = ""
+	if environment.MemoryLimit > 0 {
+		newEnvironmentMemoryLimit = strconv.Itoa(environment.MemoryLimit)
+	}
+}
+
+// setResources copies replicas and resource limits typed in the environment form, fields are validated by the form
+func setResources(environment *Environment) {
+	environment.Replicas, _ = strconv.Atoi(strings.TrimSpace(newEnvironmentReplicas))
+	environment.CPULimit, _ = strconv.ParseFloat(strings.TrimSpace(newEnvironmentCPULimit), 64)
+	environment.MemoryLimit, _ = strconv.Atoi(strings.TrimSpace(newEnvironmentMemoryLimit))
+}
+
+func validateCPULimit(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return nil
+	}
+	cpuLimit, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil || cpuLimit <= 0 || cpuLimit > 256 {
+		return errors.New("enter a number of CPU cores, for example 0.5 or 2")
+	}
+	return nil
+}
+
+func validateMemoryLimit(str string) error {
+	if strings.TrimSpace(str) == "" {
+		return nil
+	}
+	return validateNumber(16, 1024*1024)(str)
+}
+
+// allocatedMemory sums memory limits of replicas of environments on each machine in MB,
+// the edited environment is counted with the values typed in the form instead
+func allocatedMemory(environments []machineEnvironment, editedEnvironmentId string) map[string]int64 {
+	allocated := map[string]int64{}
+	for _, machineEnvironment := range environments {
+		environment := machineEnvironment.environment
+		if environment.Id == editedEnvironmentId || environment.MemoryLimit <= 0 {
+			continue
+		}
+		for _, machineId := range environment.MachineIds {
+			allocated[machineId] += int64(max(environment.Replicas, ENVIRONMENT_DEFAULT_REPLICAS) * environment.MemoryLimit)
+		}
+	}
+	return allocated
+}
+
+// resourcesWarning returns a warning if replicas of the environment together with other environments
+// on the selected machines can use more memory than the machines have, machines without stats are skipped
+func resourcesWarning(machineIds []string, machineOptions []huh.Option[string], machinesStats []MachineStats, allocated map[string]int64) string {
+	replicas, err := strconv.Atoi(strings.TrimSpace(newEnvironmentReplicas))
+	if err != nil {
+		return ""
+	}
+	memoryLimit, err := strconv.Atoi(strings.TrimSpace(newEnvironmentMemoryLimit))
+	if err != nil || memoryLimit <= 0 {
+		return ""
+	}
+
+	requested := int64(replicas * memoryLimit)
+	overcommitted := []string{}
+	for _, machineStats := range machinesStats {
+		other := allocated[machineStats.MachineId]
+		if machineStats.TotalMemory > 0 && requested+other > machineStats.TotalMemory && slices.Contains(machineIds, machineStats.MachineId) {
+			name := machineStats.MachineId
+			for _, option := range machineOptions {
+				if option.Value == machineStats.MachineId {
+					name = option.Key
+				}
+			}
+			if other > 0 {
+				overcommitted = append(overcommitted, fmt.Sprintf("%s (%d MB, %d MB are limits of other environments)", name, machineStats.TotalMemory, other))
+			} else {
+				overcommitted = append(overcommitted, fmt.Sprintf("%s (%d MB)", name, machineStats.TotalMemory))
+			}
+		}
+	}
+
+	if len(overcommitted) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠ %d × %d MB = %d MB with other environments is more than the total memory of %s, replicas can be killed when memory runs out",
+		replicas, memoryLimit, requested, strings.Join(overcommitted, ", "))
+}
+
+// resourcesGroup is a page of the environment form, the warning is updated while replicas and limits are typed
+func resourcesGroup(machineOptions []huh.Option[string], editedEnvironmentId string) *huh.Group {
+	machinesStats := getMachineStates()
+
+	machineIds := []string{}
+	for _, option := range machineOptions {
+		machineIds = append(machineIds, option.Value)
+	}
+	//Without other environments the warning only covers this one
+	environments, _ := getMachineEnvironments(machineIds)
+	allocated := allocatedMemory(environments, editedEnvironmentId)
+
+	return huh.NewGroup(
+		huh.NewInput().
+			Title("Replicas").
+			Description("Instances of the service on each server").
+			Value(&newEnvironmentReplicas).
+			Validate(validateNumber(1, 100)),
+		huh.NewInput().
+			Title("CPU Limit, cores").
+			Description("CPU available to each instance, leave it empty for no limit").
+			Placeholder("0.5, 1, 2, etc").
+			Value(&newEnvironmentCPULimit).
+			Validate(validateCPULimit),
+		huh.NewInput().
+			Title("Memory Limit, MB").
+			Description("Memory available to each instance, leave it empty for no limit").
+			Placeholder("256, 512, 1024, etc").
+			Value(&newEnvironmentMemoryLimit).
+			Validate(validateMemoryLimit),
+		huh.NewNote().
+			DescriptionFunc(func() string {
+				return resourcesWarning(newEnvironmentMachines, machineOptions, machinesStats, allocated)
+			}, []any{&newEnvironmentReplicas, &newEnvironmentMemoryLimit, &newEnvironmentMachines}),
+	)
+}
+
+// resourcesDescription describes replicas and limits, for example "2 replicas, 0.5 CPU, 512 MB"
+func resourcesDescription(environment Environment) string {
+	replicas := max(environment.Replicas, ENVIRONMENT_DEFAULT_REPLICAS)
+	description := fmt.Sprintf("%d replicas", replicas)
+	if replicas == 1 {
+		description = "1 replica"
+	}
+
+	cpuLimit := "no CPU limit"
+	if environment.CPULimit > 0 {
+		cpuLimit = strconv.FormatFloat(environment.CPULimit, 'f', -1, 64) + " CPU"
+	}
+	memoryLimit := "no memory limit"
+	if environment.MemoryLimit > 0 {
+		memoryLimit = strconv.Itoa(environment.MemoryLimit) + " MB"
+	}
+	return description + ", " + cpuLimit + ", " + memoryLimit
+}
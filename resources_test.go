@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+)
+
+func TestResourcesWarningCountsOtherEnvironments(t *testing.T) {
+	environments := []machineEnvironment{
+		{environment: Environment{Id: "e1", MachineIds: []string{"m1", "m2"}, Replicas: 2, MemoryLimit: 1024}},
+		{environment: Environment{Id: "e2", MachineIds: []string{"m1"}, MemoryLimit: 512}},
+		{environment: Environment{Id: "e3", MachineIds: []string{"m1"}}},
+	}
+	allocated := allocatedMemory(environments, "e2")
+	if allocated["m1"] != 2048 || allocated["m2"] != 2048 {
+		t.Fatalf("allocatedMemory() = %v, want 2048 MB on m1 and m2", allocated)
+	}
+
+	machineOptions := []huh.Option[string]{huh.NewOption("alpha", "m1"), huh.NewOption("beta", "m2")}
+	machinesStats := []MachineStats{{MachineId: "m1", TotalMemory: 4096}, {MachineId: "m2", TotalMemory: 8192}}
+
+	newEnvironmentReplicas, newEnvironmentMemoryLimit = "2", "1024"
+	if warning := resourcesWarning([]string{"m1", "m2"}, machineOptions, machinesStats, allocated); warning != "" {
+		t.Errorf("unexpected warning %q, 2048 MB of 4096 MB are free", warning)
+	}
+
+	newEnvironmentReplicas = "3"
+	warning := resourcesWarning([]string{"m1", "m2"}, machineOptions, machinesStats, allocated)
+	if !strings.Contains(warning, "alpha (4096 MB, 2048 MB are limits of other environments)") || strings.Contains(warning, "beta") {
+		t.Errorf("warning = %q, want only alpha", warning)
+	}
+}
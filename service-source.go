@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const DEFAULT_IMAGE_TAG = "latest"
+
+// Registry host with an optional port and lowercase path components, for example ghcr.io/acme/web or localhost:5000/web
+var imageReferenceRegexp = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*$`)
+var imageTagRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+var (
+	newServiceSource           string
+	newServiceImage            string
+	newServiceImageTag         string
+	newServiceRegistryUsername string
+	newServiceRegistryPassword string
+	newServiceDeployOnNewTag   bool
+)
+
+func isImageService(service Service) bool {
+	return service.Source == SERVICE_SOURCE_IMAGE
+}
+
+// imageReference returns the image with its tag, for example ghcr.io/acme/web:latest
+func imageReference(image ContainerImage) string {
+	return image.Reference + ":" + image.Tag
+}
+
+// serviceSource describes where the service is deployed from in the Services table
+func serviceSource(service Service) string {
+	switch {
+	case isDatabase(service):
+		return databaseSource(service)
+	case isImageService(service):
+		return imageReference(service.Image)
+	}
+	return service.GitURL
+}
+
+func validateImageReference(str string) error {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return errors.New("enter an image, for example ghcr.io/acme/web")
+	}
+	if strings.Contains(str, "@") || strings.Contains(str[strings.LastIndex(str, "/")+1:], ":") {
+		return errors.New("enter the tag in the Tag field")
+	}
+	if !imageReferenceRegexp.MatchString(str) {
+		return errors.New("use lowercase letters, digits, ., -, _ and /, for example ghcr.io/acme/web")
+	}
+	return nil
+}
+
+func validateImageTag(str string) error {
+	if !imageTagRegexp.MatchString(strings.TrimSpace(str)) {
+		return errors.New("enter a tag, for example latest or 1.4.2")
+	}
+	return nil
+}
+
+// setServiceSourceForm resets the source pages of the Add Service form, services are built from Git by default
+func setServiceSourceForm() {
+	newServiceSource = SERVICE_SOURCE_GIT
+	newServiceGitURL = ""
+	newServiceImage = ""
+	newServiceImageTag = DEFAULT_IMAGE_TAG
+	newServiceRegistryUsername = ""
+	newServiceRegistryPassword = ""
+	newServiceDeployOnNewTag = false
+}
+
+// serviceSourceField chooses between cloning a Git repository and pulling a registry image
+func serviceSourceField() huh.Field {
+	return huh.NewSelect[string]().
+		Title("Source").
+		Options(
+			huh.NewOption("Git repository, built on the builder machine", SERVICE_SOURCE_GIT),
+			huh.NewOption("Container image from a registry, no build", SERVICE_SOURCE_IMAGE),
+		).
+		Value(&newServiceSource)
+}
+
+// serviceSourceGroups are pages of the Add Service form, only the page of the chosen source is shown
+func serviceSourceGroups() []*huh.Group {
+	gitGroup := huh.NewGroup(
+		huh.NewInput().
+			Title("Git clone URL").
+			Placeholder("get@...for private repos and https://... for public repos").
+			Value(&newServiceGitURL).
+			Validate(func(str string) error {
+				/*if str == "Frank" {
+				}*/
+				return nil
+			}),
+	).WithHideFunc(func() bool {
+		return newServiceSource != SERVICE_SOURCE_GIT
+	})
+
+	imageGroup := huh.NewGroup(
+		huh.NewInput().
+			Title("Image").
+			Description("Docker Hub images can be short, for example nginx or acme/web").
+			Placeholder("ghcr.io/acme/web").
+			Value(&newServiceImage).
+			Validate(validateImageReference),
+		huh.NewInput().
+			Title("Tag").
+			Value(&newServiceImageTag).
+			Validate(validateImageTag),
+		huh.NewInput().
+			Title("Registry Username").
+			Description("Leave it empty for public images").
+			Value(&newServiceRegistryUsername),
+		huh.NewInput().
+			Title("Registry Password or Token").
+			EchoMode(huh.EchoModePassword).
+			Value(&newServiceRegistryPassword).
+			Validate(func(str string) error {
+				if strings.TrimSpace(newServiceRegistryUsername) != "" && str == "" {
+					return errors.New("enter a password or an access token of " + strings.TrimSpace(newServiceRegistryUsername))
+				}
+				return nil
+			}),
+		huh.NewConfirm().
+			Title("Deploy on New Tag").
+			Description("The lighthouse checks the registry and deploys all environments when a new image is pushed to the tag").
+			Affirmative("Yes").
+			Negative("No").
+			Value(&newServiceDeployOnNewTag),
+	).WithHideFunc(func() bool {
+		return newServiceSource != SERVICE_SOURCE_IMAGE
+	})
+
+	return []*huh.Group{gitGroup, imageGroup}
+}
+
+// newServiceFromForm returns the service typed in the Add Service form, fields of the other source are dropped
+func newServiceFromForm() Service {
+	service := Service{Name: newServiceName, ProjectId: newServiceProjectId, Source: newServiceSource}
+	if newServiceSource == SERVICE_SOURCE_IMAGE {
+		service.Image = ContainerImage{
+			Reference:        strings.TrimSpace(newServiceImage),
+			Tag:              strings.TrimSpace(newServiceImageTag),
+			RegistryUsername: strings.TrimSpace(newServiceRegistryUsername),
+			RegistryPassword: newServiceRegistryPassword,
+			DeployOnNewTag:   newServiceDeployOnNewTag,
+		}
+	} else {
+		service.GitURL = newServiceGitURL
+	}
+	return service
+}
+
+// imageEnvironmentHint replaces the deploy key and webhook hint for services deployed from a registry image
+func imageEnvironmentHint(service Service, machineBuilder Machine, environmentId string) string {
+	hint := "    Each deployment pulls " + codeHintStyle.Render(imageReference(service.Image)) + " on the servers of this environment, nothing is built.\n\n"
+	if service.Image.RegistryUsername != "" {
+		hint += "    The image is pulled with the registry credentials of " + service.Image.RegistryUsername + ".\n\n"
+	}
+
+	hint += "    Options to deploy:\n\n    • From the Environment menu: Main Menu → Services → Select Environment → Deploy\n"
+	if service.Image.DeployOnNewTag {
+		hint += "    • Push a new image to the " + service.Image.Tag + " tag, the lighthouse deploys it automatically.\n"
+	}
+	if len(machineBuilder.Domains) > 0 {
+		hint += "    • Send a GET request to https://" + machineBuilder.Domains[0] + "/deploy/environment/" + environmentId + "\n"
+	}
+	return hint + "\n    To manage environments, go to Services and select a service from the list.\n\n"
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// serviceRows returns rows of the Services table, services of other projects and filtered out services are hidden
+func serviceRows(m model) []table.Row {
+	rows := []table.Row{}
+
+	for _, service := range filterServices(m) {
+
+		var tableRow []string
+		tableRow = append(tableRow, service.Id)
+		tableRow = append(tableRow, service.Name)
+		tableRow = append(tableRow, serviceSource(service))
+		tableRow = append(tableRow, databaseHealthCell(service))
+		tableRow = append(tableRow, backupCell(service))
+
+		rows = append(rows, tableRow)
+	}
+
+	return rows
+}
+
+// serviceById returns the service from the loaded Services list
+func serviceById(m model, serviceId string) Service {
+	for _, service := range m.services {
+		if service.Id == serviceId {
+			return service
+		}
+	}
+	return Service{Id: serviceId}
+}
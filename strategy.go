@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+const MENU_DEPLOYMENT_PROGRESS = "Deployment Progress"
+
+// Actions of controlDeployment
+const DEPLOYMENT_ACTION_PAUSE = "pause"
+const DEPLOYMENT_ACTION_RESUME = "resume"
+const DEPLOYMENT_ACTION_ABORT = "abort"
+
+const ROLLING_DEFAULT_BATCH_SIZE = 1
+const CANARY_DEFAULT_PERCENTAGE = 10
+
+var (
+	newEnvironmentStrategy         string
+	newEnvironmentBatchSize        string
+	newEnvironmentCanaryPercentage string
+)
+
+type DeploymentProgressMsg struct {
+	environmentId string
+	sequence      int
+	deployment    Deployment
+	machineNames  map[string]string //loaded with the first poll, nil until machines are loaded
+	err           error
+}
+
+func deploymentStrategyLabel(strategy string) string {
+	switch strategy {
+	case DEPLOYMENT_STRATEGY_ROLLING:
+		return "Rolling"
+	case DEPLOYMENT_STRATEGY_BLUE_GREEN:
+		return "Blue/green"
+	case DEPLOYMENT_STRATEGY_CANARY:
+		return "Canary"
+	}
+	return "All at once"
+}
+
+// setDeploymentStrategyForm fills deployment strategy fields of the environment form
+func setDeploymentStrategyForm(environment Environment) {
+	newEnvironmentStrategy = environment.DeploymentStrategy
+	if newEnvironmentStrategy == "" {
+		newEnvironmentStrategy = DEPLOYMENT_STRATEGY_ALL_AT_ONCE
+	}
+
+	newEnvironmentBatchSize = strconv.Itoa(max(environment.RollingBatchSize, ROLLING_DEFAULT_BATCH_SIZE))
+	newEnvironmentCanaryPercentage = strconv.Itoa(CANARY_DEFAULT_PERCENTAGE)
+	if environment.CanaryPercentage > 0 {
+		newEnvironmentCanaryPercentage = strconv.Itoa(environment.CanaryPercentage)
+	}
+}
+
+// setDeploymentStrategy copies the strategy typed in the environment form, fields are validated by the form
+func setDeploymentStrategy(environment *Environment) {
+	environment.DeploymentStrategy = newEnvironmentStrategy
+	environment.RollingBatchSize, _ = strconv.Atoi(newEnvironmentBatchSize)
+	environment.CanaryPercentage, _ = strconv.Atoi(newEnvironmentCanaryPercentage)
+}
+
+// deploymentStrategyGroups are pages of the environment form, batch size and percentage
+// are asked only for strategies that use them
+func deploymentStrategyGroups() []*huh.Group {
+	return []*huh.Group{
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Deployment Strategy").
+				Description("How a new version rolls out when the environment runs on several servers").
+				Options(
+					huh.NewOption("All at once", DEPLOYMENT_STRATEGY_ALL_AT_ONCE),
+					huh.NewOption("Rolling, a batch of servers at a time", DEPLOYMENT_STRATEGY_ROLLING),
+					huh.NewOption("Blue/green, switch traffic once the new version is healthy everywhere", DEPLOYMENT_STRATEGY_BLUE_GREEN),
+					huh.NewOption("Canary, a share of servers first, then resume to continue", DEPLOYMENT_STRATEGY_CANARY),
+				).
+				Value(&newEnvironmentStrategy),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Batch Size").
+				Description("Servers updated at the same time").
+				Value(&newEnvironmentBatchSize).
+				Validate(validateNumber(1, 1000)),
+		).WithHideFunc(func() bool { return newEnvironmentStrategy != DEPLOYMENT_STRATEGY_ROLLING }),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Canary Percentage").
+				Description("Share of servers that get the new version first, at least one server").
+				Value(&newEnvironmentCanaryPercentage).
+				Validate(validateNumber(1, 99)),
+		).WithHideFunc(func() bool { return newEnvironmentStrategy != DEPLOYMENT_STRATEGY_CANARY }),
+	}
+}
+
+// showDeploymentProgress starts polling progress of the deployment shown on the Deployment Scheduled screen,
+// the previous polling stops because its sequence is outdated. previousDeploymentId is the latest deployment
+// before a new one was requested, it isn't reported as finished while the new one is starting
+func showDeploymentProgress(m *model, environmentId string, previousDeploymentId string) tea.Cmd {
+	m.deploymentProgressSequence++
+	m.deploymentProgress = DeploymentProgressMsg{}
+	m.previousDeploymentId = previousDeploymentId
+	m.abortRequested = false
+	return deploymentProgressCmd(environmentId, m.deploymentProgressSequence, nil)
+}
+
+func deploymentProgressCmd(environmentId string, sequence int, machineNames map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		msg := DeploymentProgressMsg{environmentId: environmentId, sequence: sequence, machineNames: machineNames}
+		msg.deployment, msg.err = getDeployment(environmentId)
+		if msg.machineNames != nil {
+			return msg
+		}
+		if machines, ok := getMachines().(MachineMsg); ok {
+			msg.machineNames = map[string]string{}
+			for _, machine := range machines {
+				msg.machineNames[machine.Id] = machine.Name
+			}
+		}
+		return msg
+	}
+}
+
+// pollDeploymentProgress polls the deployment again, machine names are loaded once
+func pollDeploymentProgress(msg DeploymentProgressMsg) tea.Cmd {
+	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
+		return deploymentProgressCmd(msg.environmentId, msg.sequence, msg.machineNames)()
+	})
+}
+
+// isDeploymentProgressFinished reports whether the shown deployment won't change anymore and polling can stop
+func isDeploymentProgressFinished(m model, msg DeploymentProgressMsg) bool {
+	deployment := msg.deployment
+	return msg.err == nil && deployment.Id != "" && deployment.Id != m.previousDeploymentId && !isDeploymentInProgress(deployment.Status)
+}
+
+// runDeploymentAction pauses, resumes or aborts the deployment on the screen
+func runDeploymentAction(m *model, action string) tea.Cmd {
+	m.abortRequested = false
+	screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+	if !controlDeployment(m.selectedEnvironment.Id, action) {
+		return showToast(m, "Cannot "+action+" the deployment, check that the lighthouse is reachable")
+	}
+	return tea.Batch(showToast(m, "Deployment of "+m.selectedEnvironment.Name+": "+action+" requested"), showDeploymentProgress(m, m.selectedEnvironment.Id, ""))
+}
+
+// controlDeploymentCmd runs the pause or the abort right away or asks for the confirmation on protected environments
+func controlDeploymentCmd(m *model, action string) tea.Cmd {
+	if needsProtectedConfirmation(*m, action) {
+		startProtectedAction(m, action)
+		return nil
+	}
+	return runDeploymentAction(m, action)
+}
+
+func isDeploymentControllable(m model) bool {
+	return m.deploymentProgress.err == nil && m.deploymentProgress.deployment.Id != "" && isDeploymentInProgress(m.deploymentProgress.deployment.Status)
+}
+
+// machineProgressView shows the strategy and the status of the deployment on each machine
+func machineProgressView(m model) string {
+	deployment := m.deploymentProgress.deployment
+	if m.deploymentProgress.err != nil || deployment.Id == "" || len(deployment.Machines) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	title := deploymentStrategyLabel(deployment.Strategy)
+	if deployment.Paused {
+		title += " · paused"
+	}
+	builder.WriteString(" " + title + "\n\n")
+
+	for _, machine := range deployment.Machines {
+		name := m.deploymentProgress.machineNames[machine.MachineId]
+		if name == "" {
+			name = machine.MachineId
+		}
+
+		status := machine.Status
+		switch {
+		case status == DEPLOYMENT_STATUS_DEPLOYED:
+			status = deploymentDeployedStyle.Render("✓ " + status)
+		case status == DEPLOYMENT_STATUS_FAILED || status == DEPLOYMENT_STATUS_ABORTED || status == DEPLOYMENT_STATUS_ROLLED_BACK:
+			status = deploymentFailedStyle.Render("✗ " + deploymentStatusLabel(status))
+		case isDeploymentInProgress(status) && !deployment.Paused:
+			status = m.spinner.View() + status
+		default:
+			status = "· " + status
+		}
+		builder.WriteString(fmt.Sprintf("   %-20s %s\n", name, status))
+	}
+
+	if isDeploymentControllable(m) {
+		hint := "Press p to pause, a to abort"
+		if deployment.Paused {
+			hint = "Press r to resume, a to abort"
+		}
+		if m.abortRequested {
+			hint = "Press a again to abort the deployment, machines keep or get back the previous version"
+		}
+		builder.WriteString("\n " + hint + "\n")
+	}
+
+	return builder.String()
+}
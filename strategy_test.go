@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Machine names are loaded with the first poll only
+func TestDeploymentProgressLoadsMachinesOnce(t *testing.T) {
+	machineRequests := 0
+	useLighthouse(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/machine":
+			machineRequests++
+			json.NewEncoder(w).Encode([]Machine{{Id: "m1", Name: "alpha"}})
+		case "/environment/e1/deployment":
+			json.NewEncoder(w).Encode(Deployment{Id: "d1", Status: DEPLOYMENT_STATUS_DEPLOYING})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	msg := deploymentProgressCmd("e1", 1, nil)().(DeploymentProgressMsg)
+	msg = deploymentProgressCmd(msg.environmentId, msg.sequence, msg.machineNames)().(DeploymentProgressMsg)
+	if machineRequests != 1 {
+		t.Errorf("machines loaded %d times, want once", machineRequests)
+	}
+	if msg.machineNames["m1"] != "alpha" {
+		t.Errorf("machine names = %v", msg.machineNames)
+	}
+}
+
+func TestIsDeploymentProgressFinished(t *testing.T) {
+	m := model{previousDeploymentId: "d1"}
+	tests := []struct {
+		deployment Deployment
+		finished   bool
+	}{
+		{Deployment{}, false},
+		{Deployment{Id: "d1", Status: DEPLOYMENT_STATUS_DEPLOYED}, false},
+		{Deployment{Id: "d2", Status: DEPLOYMENT_STATUS_DEPLOYING}, false},
+		{Deployment{Id: "d2", Status: DEPLOYMENT_STATUS_DEPLOYED}, true},
+		{Deployment{Id: "d2", Status: DEPLOYMENT_STATUS_FAILED}, true},
+	}
+	for _, test := range tests {
+		if finished := isDeploymentProgressFinished(m, DeploymentProgressMsg{deployment: test.deployment}); finished != test.finished {
+			t.Errorf("isDeploymentProgressFinished(%+v) = %v, want %v", test.deployment, finished, test.finished)
+		}
+	}
+}
+
+// Pausing and aborting deployments of protected environments requires the confirmation phrase
+func TestControlDeploymentOfProtectedEnvironment(t *testing.T) {
+	m := newModel()
+	m.selectedEnvironment = Environment{Id: "e2", Name: "prod"}
+	m.environments = []Environment{{Id: "e2", Name: "prod", Protected: true}}
+
+	for _, action := range []string{DEPLOYMENT_ACTION_PAUSE, DEPLOYMENT_ACTION_ABORT} {
+		screenType = SCREEN_TYPE_DEPLOYMENT_SCHEDULED
+		if cmd := controlDeploymentCmd(&m, action); cmd != nil || screenType != SCREEN_TYPE_PROTECTED_ACTION {
+			t.Fatalf("%s ran without the confirmation", action)
+		}
+		if phrase := protectedActionPhrase(m); phrase != action+" prod" {
+			t.Errorf("phrase = %q, want %q", phrase, action+" prod")
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+const SORT_BY_NAME = "Name"
+const SORT_BY_STATUS = "Status"
+const SORT_BY_CPU = "CPU"
+const SORT_BY_DISK = "Disk"
+
+var (
+	machineSortColumns     = []string{SORT_BY_NAME, SORT_BY_STATUS, SORT_BY_CPU, SORT_BY_DISK}
+	serviceSortColumns     = []string{SORT_BY_NAME}
+	environmentSortColumns = []string{SORT_BY_NAME, SORT_BY_STATUS}
+
+	tableFilterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#bfbfbf"))
+)
+
+// tableFilter keeps the search, sort and status filter of a table, it's stored in the model
+// so the table keeps them when it's reloaded every 2 seconds
+type tableFilter struct {
+	input  textinput.Model
+	sortBy string
+	status string
+}
+
+func newTableFilter() tableFilter {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search"
+	input.CharLimit = 64
+	return tableFilter{input: input}
+}
+
+// activeTableFilter returns the filter of the table on the screen or nil
+func activeTableFilter(m *model) *tableFilter {
+	switch screenType {
+	case SCREEN_TYPE_MACHINES:
+		return &m.machineFilter
+	case 5:
+		return &m.serviceFilter
+	case SCREEN_TYPE_ENVIRONMENTS:
+		return &m.environmentFilter
+	}
+	return nil
+}
+
+// updateTableFilterInput handles keys while the search query is typed
+func updateTableFilterInput(m *model, filter *tableFilter, msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "enter":
+		filter.input.Blur()
+	case "esc":
+		filter.input.SetValue("")
+		filter.input.Blur()
+	default:
+		filter.input, cmd = filter.input.Update(msg)
+	}
+
+	refreshTableRows(m)
+	return cmd
+}
+
+// refreshTableRows applies the filter to the table on the screen
+func refreshTableRows(m *model) {
+	switch screenType {
+	case SCREEN_TYPE_MACHINES:
+		setRowsKeepingSelection(&m.machineList, machineRows(*m))
+	case 5:
+		setRowsKeepingSelection(&m.serviceList, serviceRows(*m))
+	case SCREEN_TYPE_ENVIRONMENTS:
+		setRowsKeepingSelection(&m.environmentList, environmentRows(*m))
+	}
+}
+
+// setRowsKeepingSelection keeps the cursor on the same ID if the row is still visible
+func setRowsKeepingSelection(t *table.Model, rows []table.Row) {
+	selectedRow := t.SelectedRow()
+	t.SetRows(rows)
+
+	indexToSelect := 0
+	for index, row := range rows {
+		if selectedRow != nil && selectedRow[0] == row[0] {
+			indexToSelect = index
+		}
+	}
+	t.SetCursor(indexToSelect)
+}
+
+// nextOption returns the option after the current one, an empty value means none or all
+func nextOption(options []string, current string) string {
+	options = append([]string{""}, options...)
+	index := slices.Index(options, current)
+	return options[(index+1)%len(options)]
+}
+
+// statusOptions returns statuses to filter by, the current one is kept even if no row has it anymore
+func statusOptions(statuses []string, current string) []string {
+	options := []string{}
+	for _, status := range append(statuses, current) {
+		if status != "" && !slices.Contains(options, status) {
+			options = append(options, status)
+		}
+	}
+	slices.Sort(options)
+	return options
+}
+
+// fuzzyMatches returns indexes of names that match the search query, nil means everything matches
+func fuzzyMatches(query string, names []string) map[int]bool {
+	if query == "" {
+		return nil
+	}
+
+	matches := map[int]bool{}
+	for _, match := range fuzzy.Find(query, names) {
+		matches[match.Index] = true
+	}
+	return matches
+}
+
+func compareNames(a string, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// usage parses CPU and disk usage, machines without stats go last
+func usage(value string) int {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return number
+}
+
+// filterMachines returns machines shown in the Machines table
+func filterMachines(m model) []Machine {
+	machines := []Machine{}
+	names := []string{}
+	for _, machine := range m.machines {
+		if !isMachineInProject(m, machine) {
+			continue
+		}
+		if m.machineFilter.status != "" && machineStatus(m, machine) != m.machineFilter.status {
+			continue
+		}
+		machines = append(machines, machine)
+		names = append(names, machine.Name)
+	}
+
+	if matches := fuzzyMatches(m.machineFilter.input.Value(), names); matches != nil {
+		filtered := []Machine{}
+		for index, machine := range machines {
+			if matches[index] {
+				filtered = append(filtered, machine)
+			}
+		}
+		machines = filtered
+	}
+
+	switch m.machineFilter.sortBy {
+	case SORT_BY_NAME:
+		slices.SortStableFunc(machines, func(a, b Machine) int { return compareNames(a.Name, b.Name) })
+	case SORT_BY_STATUS:
+		slices.SortStableFunc(machines, func(a, b Machine) int { return compareNames(machineStatus(m, a), machineStatus(m, b)) })
+	case SORT_BY_CPU:
+		//The busiest machines first
+		slices.SortStableFunc(machines, func(a, b Machine) int { return cmp.Compare(usage(b.CPUUsage), usage(a.CPUUsage)) })
+	case SORT_BY_DISK:
+		//The disk column shows available space, machines running out of space first
+		slices.SortStableFunc(machines, func(a, b Machine) int {
+			if usage(a.DiskUsage) < 0 || usage(b.DiskUsage) < 0 {
+				return cmp.Compare(usage(b.DiskUsage), usage(a.DiskUsage))
+			}
+			return cmp.Compare(usage(a.DiskUsage), usage(b.DiskUsage))
+		})
+	}
+
+	return machines
+}
+
+// filterServices returns services shown in the Services table
+func filterServices(m model) []Service {
+	services := []Service{}
+	names := []string{}
+	for _, service := range m.services {
+		if m.selectedProject.Id != "" && service.ProjectId != m.selectedProject.Id {
+			continue
+		}
+		services = append(services, service)
+		names = append(names, service.Name)
+	}
+
+	if matches := fuzzyMatches(m.serviceFilter.input.Value(), names); matches != nil {
+		filtered := []Service{}
+		for index, service := range services {
+			if matches[index] {
+				filtered = append(filtered, service)
+			}
+		}
+		services = filtered
+	}
+
+	if m.serviceFilter.sortBy == SORT_BY_NAME {
+		slices.SortStableFunc(services, func(a, b Service) int { return compareNames(a.Name, b.Name) })
+	}
+
+	return services
+}
+
+// filterEnvironments returns environments shown in the Environments table
+func filterEnvironments(m model) []Environment {
+	environments := []Environment{}
+	names := []string{}
+	for _, environment := range m.environments {
+		if m.environmentFilter.status != "" && environment.LastDeploymentStatus != m.environmentFilter.status {
+			continue
+		}
+		environments = append(environments, environment)
+		names = append(names, environment.Name)
+	}
+
+	if matches := fuzzyMatches(m.environmentFilter.input.Value(), names); matches != nil {
+		filtered := []Environment{}
+		for index, environment := range environments {
+			if matches[index] {
+				filtered = append(filtered, environment)
+			}
+		}
+		environments = filtered
+	}
+
+	switch m.environmentFilter.sortBy {
+	case SORT_BY_NAME:
+		slices.SortStableFunc(environments, func(a, b Environment) int { return compareNames(a.Name, b.Name) })
+	case SORT_BY_STATUS:
+		slices.SortStableFunc(environments, func(a, b Environment) int {
+			return compareNames(a.LastDeploymentStatus, b.LastDeploymentStatus)
+		})
+	}
+
+	return environments
+}
+
+func machineStatuses(m model) []string {
+	statuses := []string{}
+	for _, machine := range m.machines {
+		statuses = append(statuses, machineStatus(m, machine))
+	}
+	return statusOptions(statuses, m.machineFilter.status)
+}
+
+func environmentStatuses(m model) []string {
+	statuses := []string{}
+	for _, environment := range m.environments {
+		statuses = append(statuses, environment.LastDeploymentStatus)
+	}
+	return statusOptions(statuses, m.environmentFilter.status)
+}
+
+// tableFilterView renders the search, sort and status filter below the table
+func tableFilterView(filter tableFilter, withStatus bool) string {
+	parts := []string{}
+
+	if filter.input.Focused() || filter.input.Value() != "" {
+		parts = append(parts, filter.input.View())
+	} else {
+		parts = append(parts, "/ search")
+	}
+
+	sortBy := filter.sortBy
+	if sortBy == "" {
+		sortBy = "none"
+	}
+	parts = append(parts, "s sort: "+sortBy)
+
+	if withStatus {
+		status := filter.status
+		if status == "" {
+			status = "all"
+		}
+		parts = append(parts, "f status: "+status)
+	}
+
+	return listHelpStyle.Render(tableFilterStyle.Render(strings.Join(parts, " • ")))
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Deletions are held back for this long and can be undone in the meantime
+const UNDO_DELAY = 5 * time.Second
+
+const DELETE_TARGET_ENVIRONMENT = "environment"
+const DELETE_TARGET_SERVICE = "service"
+const DELETE_TARGET_MACHINE = "machine"
+
+var pendingDeleteStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FFFDF5")).
+	Background(lipgloss.Color("#e07a5f")).
+	Padding(0, 1)
+
+type pendingDelete struct {
+	sequence    int //ticks of cancelled deletions are ignored
+	target      string
+	resourceIds []string //several resources are deleted at once by bulk actions
+	names       []string
+	serviceId   string
+	deadline    time.Time
+}
+
+type PendingDeleteTickMsg struct {
+	sequence int
+}
+
+type DeletedMsg struct {
+	target    string
+	deleted   []string //names of deleted resources
+	failed    []string
+	serviceId string
+}
+
+// confirmationPhrase returns what has to be typed to confirm deletion,
+// protected resources require their name instead of 'y'
+func confirmationPhrase(protected bool, name string) string {
+	if protected {
+		return name
+	}
+	return "y"
+}
+
+func isConfirmed(value string, phrase string) bool {
+	if phrase == "y" {
+		return strings.ToLower(value) == "y"
+	}
+	return value == phrase
+}
+
+func confirmationHint(phrase string) string {
+	if phrase == "y" {
+		return "Type 'y' to confirm or press ESC to cancel."
+	}
+	return "Type '" + phrase + "' to confirm or press ESC to cancel."
+}
+
+// isProtectedEnvironment reports whether the environment is marked as protected or serves traffic on a domain
+func isProtectedEnvironment(environment Environment) bool {
+	return environment.Protected || hasDomain(environment.Domains) && environment.LastDeploymentCommit != ""
+}
+
+// hasDomain reports whether any of the domains is set, environments created without
+// a domain by older versions of the CLI have an empty one
+func hasDomain(domains []string) bool {
+	return slices.ContainsFunc(domains, func(domain string) bool {
+		return strings.TrimSpace(domain) != ""
+	})
+}
+
+func isProtectedService(environments []Environment) bool {
+	for _, environment := range environments {
+		if isProtectedEnvironment(environment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isProtectedMachine reports whether the machine runs environments or has a critical type
+func isProtectedMachine(impact MachineDeleteImpactMsg) bool {
+	if len(impact.environments) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(criticalMachineTypes, func(machineType string) bool {
+		return slices.Contains(impact.machine.Types, machineType)
+	})
+}
+
+func environmentDeletePhrase(m model) string {
+	return confirmationPhrase(isProtectedEnvironment(environmentForDeployment(m, m.selectedEnvironment.Id)), m.selectedEnvironment.Name)
+}
+
+// serviceDeletePhrase requires typing the name of services serving traffic and of databases,
+// data of a database is deleted with it even when no environment is linked
+func serviceDeletePhrase(m model) string {
+	return confirmationPhrase(isDatabase(m.selectedService) || isProtectedService(m.environments), m.selectedService.Name)
+}
+
+func machineDeletePhrase(m model) string {
+	return confirmationPhrase(isProtectedMachine(m.machineDeleteImpact), m.selectedMachine.Name)
+}
+
+// deleteTargetTitle returns the target in the plural for several resources
+func deleteTargetTitle(target string, count int) string {
+	if count == 1 {
+		return target
+	}
+	return target + "s"
+}
+
+// scheduleDelete holds back the deletion for UNDO_DELAY, a deletion that is already pending runs right away
+func scheduleDelete(m *model, target string, resourceId string, name string, serviceId string) tea.Cmd {
+	return scheduleDeletes(m, target, []string{resourceId}, []string{name}, serviceId)
+}
+
+// scheduleDeletes holds back deletion of several resources of the same type, they are undone together
+func scheduleDeletes(m *model, target string, resourceIds []string, names []string, serviceId string) tea.Cmd {
+	var cmds []tea.Cmd
+	if m.pendingDelete != nil {
+		cmds = append(cmds, runDelete(*m.pendingDelete))
+	}
+
+	m.pendingDeleteSequence++
+	m.pendingDelete = &pendingDelete{
+		sequence:    m.pendingDeleteSequence,
+		target:      target,
+		resourceIds: resourceIds,
+		names:       names,
+		serviceId:   serviceId,
+		deadline:    time.Now().Add(UNDO_DELAY),
+	}
+
+	cmds = append(cmds, pendingDeleteTick(m.pendingDeleteSequence))
+	return tea.Batch(cmds...)
+}
+
+func pendingDeleteTick(sequence int) tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return PendingDeleteTickMsg{sequence: sequence}
+	})
+}
+
+// updatePendingDelete updates the countdown and runs the deletion when it ends
+func updatePendingDelete(m *model, msg PendingDeleteTickMsg) tea.Cmd {
+	if m.pendingDelete == nil || m.pendingDelete.sequence != msg.sequence {
+		return nil
+	}
+	if time.Now().Before(m.pendingDelete.deadline) {
+		return pendingDeleteTick(msg.sequence)
+	}
+
+	pending := *m.pendingDelete
+	m.pendingDelete = nil
+	return runDelete(pending)
+}
+
+func runDelete(pending pendingDelete) tea.Cmd {
+	return func() tea.Msg {
+		msg := DeletedMsg{target: pending.target, serviceId: pending.serviceId}
+		for index, resourceId := range pending.resourceIds {
+			ok := false
+			switch pending.target {
+			case DELETE_TARGET_ENVIRONMENT:
+				ok = deleteEnvironment(resourceId)
+			case DELETE_TARGET_SERVICE:
+				ok = deleteService(resourceId)
+			case DELETE_TARGET_MACHINE:
+				ok = deleteMachine(resourceId)
+			}
+			if ok {
+				msg.deleted = append(msg.deleted, pending.names[index])
+			} else {
+				msg.failed = append(msg.failed, pending.names[index])
+			}
+		}
+		return msg
+	}
+}
+
+func undoDelete(m *model) tea.Cmd {
+	name := strings.Join(m.pendingDelete.names, ", ")
+	m.pendingDelete = nil
+	return showToast(m, "Deletion of "+name+" has been cancelled")
+}
+
+// deletedToast tells which resources have been deleted and which failed
+func deletedToast(msg DeletedMsg) string {
+	texts := []string{}
+	if len(msg.deleted) > 0 {
+		title := deleteTargetTitle(msg.target, len(msg.deleted))
+		verb := " has been deleted"
+		if len(msg.deleted) > 1 {
+			verb = " have been deleted"
+		}
+		texts = append(texts, strings.ToUpper(title[:1])+title[1:]+" "+strings.Join(msg.deleted, ", ")+verb)
+	}
+	if len(msg.failed) > 0 {
+		texts = append(texts, "Cannot delete "+deleteTargetTitle(msg.target, len(msg.failed))+" "+strings.Join(msg.failed, ", "))
+	}
+	return strings.Join(texts, ". ")
+}
+
+// deletedListCmd reloads the list that showed the deleted resource
+func deletedListCmd(m model, msg DeletedMsg) tea.Cmd {
+	switch {
+	case msg.target == DELETE_TARGET_ENVIRONMENT && screenType == SCREEN_TYPE_ENVIRONMENTS && msg.serviceId == m.selectedService.Id:
+		return getEnvironmentsCmd(msg.serviceId)
+	case msg.target == DELETE_TARGET_SERVICE && screenType == 5:
+		return getServices
+	case msg.target == DELETE_TARGET_MACHINE && screenType == SCREEN_TYPE_MACHINES:
+		return getMachines
+	}
+	return nil
+}
+
+// isUndoScreen reports whether u undoes the deletion, screens with text inputs need the key
+func isUndoScreen() bool {
+	switch screenType {
+	case 1, SCREEN_TYPE_MACHINES, 5, SCREEN_TYPE_ENVIRONMENTS, SCREEN_TYPE_ENV_MENU, SCREEN_TYPE_MACHINE_MENU, SCREEN_TYPE_PROJECTS:
+		return true
+	}
+	return false
+}
+
+func pendingDeleteView(m model) string {
+	if m.pendingDelete == nil {
+		return ""
+	}
+	remaining := max(0, int(time.Until(m.pendingDelete.deadline).Round(time.Second).Seconds()))
+	title := deleteTargetTitle(m.pendingDelete.target, len(m.pendingDelete.names))
+	return "\n" + toastPositionStyle.Render(pendingDeleteStyle.Render(fmt.Sprintf("Deleting %s %s in %ds, press u to undo", title, strings.Join(m.pendingDelete.names, ", "), remaining))) + "\n"
+}
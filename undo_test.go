@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestIsProtectedEnvironment(t *testing.T) {
+	tests := []struct {
+		environment Environment
+		protected   bool
+	}{
+		{Environment{Name: "dev"}, false},
+		{Environment{Name: "dev", Domains: []string{""}, LastDeploymentCommit: "abc"}, false},
+		{Environment{Name: "dev", Domains: []string{" "}, LastDeploymentCommit: "abc"}, false},
+		{Environment{Name: "staging", Domains: []string{"staging.example.com"}}, false},
+		{Environment{Name: "staging", Domains: []string{"", "staging.example.com"}, LastDeploymentCommit: "abc"}, true},
+		{Environment{Name: "prod", Protected: true}, true},
+	}
+	for _, test := range tests {
+		if protected := isProtectedEnvironment(test.environment); protected != test.protected {
+			t.Errorf("isProtectedEnvironment(%+v) = %v, want %v", test.environment, protected, test.protected)
+		}
+	}
+}
+
+// Databases have no environments of their own, the name is typed to delete them anyway
+func TestServiceDeletePhraseOfDatabase(t *testing.T) {
+	m := model{selectedService: Service{Id: "db9", Name: "pg", DatabaseEngine: "postgres"}}
+	if phrase := serviceDeletePhrase(m); phrase != "pg" {
+		t.Errorf("serviceDeletePhrase() = %q, want pg", phrase)
+	}
+
+	m.selectedService = Service{Id: "s1", Name: "web"}
+	if phrase := serviceDeletePhrase(m); phrase != "y" {
+		t.Errorf("serviceDeletePhrase() = %q, want y for a service without environments", phrase)
+	}
+}
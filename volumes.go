@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+const MENU_VOLUMES = "Volumes"
+
+var volumeNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+var volumeSizeRegexp = regexp.MustCompile(`^[0-9]+(MB|GB|TB)$`)
+
+var (
+	volumes       string
+	volumesIsSave bool
+)
+
+// parseVolumes parses volumes typed one per line, for example "uploads /app/uploads 5GB", the size is optional
+func parseVolumes(value string) ([]Volume, error) {
+	parsed := []Volume{}
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 3 || len(fields) < 2 {
+			return nil, errors.New("enter a name, a mount path and an optional size on each line, for example: uploads /app/uploads 5GB")
+		}
+
+		volume := Volume{Name: fields[0], MountPath: fields[1]}
+		if len(fields) == 3 {
+			volume.SizeHint = strings.ToUpper(fields[2])
+		}
+
+		if !volumeNameRegexp.MatchString(volume.Name) {
+			return nil, errors.New(volume.Name + " is not a valid name, use lowercase letters, digits, - and _")
+		}
+		if !strings.HasPrefix(volume.MountPath, "/") || volume.MountPath == "/" {
+			return nil, errors.New("the mount path of " + volume.Name + " should start with / and can't be /")
+		}
+		if volume.SizeHint != "" && !volumeSizeRegexp.MatchString(volume.SizeHint) {
+			return nil, errors.New("the size of " + volume.Name + " should look like 500MB, 5GB or 1TB")
+		}
+		for _, other := range parsed {
+			if other.Name == volume.Name {
+				return nil, errors.New(volume.Name + " is used twice")
+			}
+			if other.MountPath == volume.MountPath {
+				return nil, errors.New(volume.MountPath + " is used by " + other.Name + " and " + volume.Name)
+			}
+		}
+
+		parsed = append(parsed, volume)
+	}
+	return parsed, nil
+}
+
+// keepVolumeBackups copies backup schedules of volumes that are kept, the volumes form doesn't edit them
+func keepVolumeBackups(volumes []Volume, existing []Volume) {
+	for index := range volumes {
+		for _, volume := range existing {
+			if volume.Name == volumes[index].Name {
+				volumes[index].Backup = volume.Backup
+			}
+		}
+	}
+}
+
+func volumesString(volumes []Volume) string {
+	lines := []string{}
+	for _, volume := range volumes {
+		lines = append(lines, strings.TrimSpace(volume.Name+" "+volume.MountPath+" "+volume.SizeHint))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// volumesUsage describes disk usage of volumes, for example "uploads 120 MB of 5GB, db 3 MB"
+func volumesUsage(volumes []Volume) string {
+	usage := []string{}
+	for _, volume := range volumes {
+		line := fmt.Sprintf("%s %d MB", volume.Name, volume.Used)
+		if volume.SizeHint != "" {
+			line += " of " + volume.SizeHint
+		}
+		usage = append(usage, line)
+	}
+	return strings.Join(usage, ", ")
+}
+
+// createVolumesForm asks for volumes of the environment, data of volumes is kept across deployments
+func createVolumesForm(m *model, environment Environment) {
+
+	volumes = volumesString(environment.Volumes)
+	volumesIsSave = true
+
+	description := "One per line: name, mount path inside the container and an optional size, for example: uploads /app/uploads 5GB"
+	if len(environment.Volumes) > 0 {
+		description += "\nUsed on each server: " + volumesUsage(environment.Volumes)
+	}
+
+	m.volumesForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewText().
+				Title("Volumes").
+				Description(description).
+				Placeholder("uploads /app/uploads 5GB").
+				Value(&volumes).
+				Validate(func(str string) error {
+					_, err := parseVolumes(str)
+					return err
+				}),
+			huh.NewConfirm().
+				Key("done").
+				Title("Save volumes?").
+				Description("Data of removed volumes is deleted from servers").
+				Affirmative("Save").
+				Negative("Cancel").
+				Value(&volumesIsSave),
+		),
+	).WithHeight(m.screenHeight - 14)
+
+	m.volumesForm.Init()
+}
+
+// environmentVolumesView warns that data of volumes is deleted with the environment
+func environmentVolumesView(environment Environment) string {
+	if len(environment.Volumes) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(" This environment owns volumes, their data will be deleted from servers:\n\n")
+	for _, volume := range environment.Volumes {
+		builder.WriteString(fmt.Sprintf(" • %s %s - %d MB used\n", volume.Name, volume.MountPath, volume.Used))
+	}
+	return builder.String()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVolumes(t *testing.T) {
+	parsed, err := parseVolumes("uploads /app/uploads 5gb\n\n  cache /app/cache  \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Volume{{Name: "uploads", MountPath: "/app/uploads", SizeHint: "5GB"}, {Name: "cache", MountPath: "/app/cache"}}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("parseVolumes() = %+v, want %+v", parsed, want)
+	}
+
+	for _, value := range []string{
+		"uploads",
+		"uploads /app/uploads 5GB extra",
+		"Uploads /app/uploads",
+		"uploads app/uploads",
+		"uploads /",
+		"uploads /app/uploads 5XB",
+		"uploads /app/uploads\nuploads /app/files",
+		"uploads /app/uploads\nfiles /app/uploads",
+	} {
+		if _, err := parseVolumes(value); err == nil {
+			t.Errorf("parseVolumes(%q) returned no error", value)
+		}
+	}
+}